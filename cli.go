@@ -0,0 +1,112 @@
+/*
+ * owobot - Your server's guardian and entertainer
+ * Copyright (C) 2023 owobot Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"go.elara.ws/owobot/internal/systems/plugins"
+)
+
+const usage = `Usage:
+  owobot                                  Run the bot
+  owobot plugin test <path> <fixture>     Test a plugin against a fixture file
+  owobot plugin types [output]            Write TypeScript declarations for the plugin API
+                                          to output, or to stdout if it's not provided
+  owobot plugin install <name>            Install or update a plugin from the plugin registry
+`
+
+// runCLI runs the subcommand in args and returns the exit code
+func runCLI(args []string) int {
+	if len(args) == 4 && args[0] == "plugin" && args[1] == "test" {
+		return pluginTest(args[2], args[3])
+	} else if len(args) >= 2 && len(args) <= 3 && args[0] == "plugin" && args[1] == "types" {
+		return pluginTypes(args[2:])
+	} else if len(args) == 3 && args[0] == "plugin" && args[1] == "install" {
+		return pluginInstall(args[2])
+	}
+
+	fmt.Fprint(os.Stderr, usage)
+	return 2
+}
+
+// pluginTest handles the `owobot plugin test` subcommand
+func pluginTest(path, fixturePath string) int {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error loading configuration:", err)
+		return 1
+	}
+
+	err = setupLogging(cfg.Log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error setting up logging:", err)
+		return 1
+	}
+
+	err = plugins.Test(path, fixturePath, cfg.Plugins, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+// pluginTypes handles the `owobot plugin types` subcommand
+func pluginTypes(args []string) int {
+	w := os.Stdout
+	if len(args) > 0 {
+		fl, err := os.Create(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error creating output file:", err)
+			return 1
+		}
+		defer fl.Close()
+		w = fl
+	}
+
+	err := plugins.WriteTypes(w)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error writing types:", err)
+		return 1
+	}
+
+	return 0
+}
+
+// pluginInstall handles the `owobot plugin install` subcommand
+func pluginInstall(name string) int {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error loading configuration:", err)
+		return 1
+	}
+
+	path, entry, err := plugins.Install(cfg.PluginDir, cfg.Plugins, name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error installing plugin:", err)
+		return 1
+	}
+
+	fmt.Printf("Installed %s (%s) to %s\n", entry.Name, entry.Version, path)
+	fmt.Println("If owobot is running, it will load the plugin the next time it checks the plugin directory for changes.")
+	return 0
+}
@@ -24,13 +24,19 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/caarlos0/env/v10"
 	"github.com/pelletier/go-toml/v2"
+	"go.elara.ws/owobot/internal/systems/plugins"
 )
 
 type Config struct {
-	Token     string   `env:"TOKEN" toml:"token"`
-	DBPath    string   `env:"DB_PATH" toml:"db_path"`
-	PluginDir string   `env:"PLUGIN_DIR" toml:"plugin_dir"`
-	Activity  Activity `envPrefix:"ACTIVITY_" toml:"activity"`
+	Token     string         `env:"TOKEN" toml:"token"`
+	DBPath    string         `env:"DB_PATH" toml:"db_path"`
+	PluginDir string         `env:"PLUGIN_DIR" toml:"plugin_dir"`
+	Plugins   plugins.Config `envPrefix:"PLUGINS_" toml:"plugins"`
+	Activity  Activity       `envPrefix:"ACTIVITY_" toml:"activity"`
+	Log       Log            `envPrefix:"LOG_" toml:"log"`
+	Shards    Shards         `envPrefix:"SHARDS_" toml:"shards"`
+	// Interactions configures the optional HTTP interactions endpoint
+	Interactions Interactions `envPrefix:"INTERACTIONS_" toml:"interactions"`
 }
 
 type Activity struct {
@@ -38,16 +44,59 @@ type Activity struct {
 	Name string                 `env:"NAME" toml:"name"`
 }
 
+type Log struct {
+	// Format is the output format of the logs, either "pretty" or "json"
+	Format string `env:"FORMAT" toml:"format"`
+	// Level is the minimum level of the messages that are logged
+	Level string `env:"LEVEL" toml:"level"`
+}
+
+type Shards struct {
+	// Count is the total number of shards, or "auto"
+	// to use the number recommended by Discord
+	Count string `env:"COUNT" toml:"count"`
+	// IDs contains the IDs of the shards run by this process.
+	// If it's empty, all the shards are run.
+	IDs []int `env:"IDS" toml:"ids"`
+}
+
+// Interactions configures the HTTP interactions endpoint. Discord sends every
+// interaction to the same endpoint, so when shards are split across processes,
+// the process serving it also handles interactions for guilds whose shards it
+// doesn't run. Plugins enabled or disabled there may take up to a minute to be
+// seen by the process that runs the guild's shard, and vice versa.
+type Interactions struct {
+	// Addr is the address the HTTP interactions endpoint listens on.
+	// An empty value disables the endpoint.
+	Addr string `env:"ADDR" toml:"addr"`
+	// Path is the path of the interactions endpoint
+	Path string `env:"PATH" toml:"path"`
+	// PublicKey is the hex-encoded public key of the
+	// application, used to verify interaction requests
+	PublicKey string `env:"PUBLIC_KEY" toml:"public_key"`
+}
+
 func loadConfig() (*Config, error) {
 	// Create a new config struct with default values
 	cfg := &Config{
 		Token:     "",
 		DBPath:    "owobot.db",
 		PluginDir: "plugins",
+		Plugins:   plugins.DefaultConfig,
 		Activity: Activity{
 			Type: -1,
 			Name: "",
 		},
+		Log: Log{
+			Format: "pretty",
+			Level:  "info",
+		},
+		Shards: Shards{
+			Count: "1",
+		},
+		Interactions: Interactions{
+			Path: "/interactions",
+		},
 	}
 
 	configPath := os.Getenv("OWOBOT_CONFIG_PATH")
@@ -0,0 +1,71 @@
+/*
+ * owobot - Your server's guardian and entertainer
+ * Copyright (C) 2023 owobot Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/logger/log"
+	"go.elara.ws/owobot/internal/systems/commands"
+)
+
+// interactionsHandler creates the handler for the HTTP interactions endpoint.
+// It has to be created before the session is used, since it changes the
+// transport of the session's HTTP client.
+func interactionsHandler(s *discordgo.Session, cfg Interactions) (http.Handler, error) {
+	key, err := hex.DecodeString(cfg.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("interactions public key: %w", err)
+	} else if len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("interactions public key: invalid ed25519 public key")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, commands.HTTPHandler(s, ed25519.PublicKey(key)))
+	return mux, nil
+}
+
+// serveInteractions serves the HTTP interactions endpoint until ctx is canceled
+func serveInteractions(ctx context.Context, addr string, handler http.Handler) {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Info("Serving HTTP interactions endpoint").Str("addr", addr).Send()
+	err := srv.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error("Error serving HTTP interactions endpoint").Err(err).Send()
+	}
+}
@@ -38,6 +38,7 @@ type Guild struct {
 	WelcomeChanID    string      `db:"welcome_chan_id"`
 	WelcomeMsg       string      `db:"welcome_msg"`
 	EnabledPlugins   StringSlice `db:"enabled_plugins"`
+	PluginErrorLog   bool        `db:"plugin_error_log"`
 }
 
 func AllGuilds() ([]Guild, error) {
@@ -107,6 +108,11 @@ func SetWelcomeMsg(guildID, msg string) error {
 	return err
 }
 
+func SetPluginErrorLog(guildID string, enabled bool) error {
+	_, err := db.Exec("UPDATE guilds SET plugin_error_log = ? WHERE id = ?", enabled, guildID)
+	return err
+}
+
 func EnablePlugin(guildID, pluginName string) error {
 	var enabledPlugins StringSlice
 	err := db.QueryRow("SELECT enabled_plugins FROM guilds WHERE id = ?", guildID).Scan(&enabledPlugins)
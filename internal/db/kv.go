@@ -0,0 +1,76 @@
+/*
+ * owobot - Your server's guardian and entertainer
+ * Copyright (C) 2023 owobot Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package db
+
+import "time"
+
+type KVEntry struct {
+	Key   string `db:"key"`
+	Value string `db:"value"`
+}
+
+func KVGet(plugin, guildID, key string) (string, error) {
+	var out string
+	err := db.QueryRow(
+		"SELECT value FROM plugin_kv WHERE plugin = ? AND guild_id = ? AND key = ? AND (expires IS NULL OR expires > ?)",
+		plugin, guildID, key, time.Now().Unix(),
+	).Scan(&out)
+	return out, err
+}
+
+// KVSet sets a key/value pair for a plugin. If expires is the zero time,
+// the entry never expires. Any of the plugin's entries in the guild that
+// have already expired are removed.
+func KVSet(plugin, guildID, key, value string, expires time.Time) error {
+	_, err := db.Exec(
+		"DELETE FROM plugin_kv WHERE plugin = ? AND guild_id = ? AND expires <= ?",
+		plugin, guildID, time.Now().Unix(),
+	)
+	if err != nil {
+		return err
+	}
+
+	var expiresUnix *int64
+	if !expires.IsZero() {
+		unix := expires.Unix()
+		expiresUnix = &unix
+	}
+
+	_, err = db.Exec(
+		"INSERT INTO plugin_kv (plugin, guild_id, key, value, expires) VALUES (?, ?, ?, ?, ?)",
+		plugin, guildID, key, value, expiresUnix,
+	)
+	return err
+}
+
+func KVDelete(plugin, guildID, key string) error {
+	_, err := db.Exec("DELETE FROM plugin_kv WHERE plugin = ? AND guild_id = ? AND key = ?", plugin, guildID, key)
+	return err
+}
+
+// KVList returns all the unexpired entries for a plugin whose keys start with prefix
+func KVList(plugin, guildID, prefix string) ([]KVEntry, error) {
+	var out []KVEntry
+	err := db.Select(
+		&out,
+		"SELECT key, value FROM plugin_kv WHERE plugin = ? AND guild_id = ? AND substr(key, 1, length(?)) = ? AND (expires IS NULL OR expires > ?) ORDER BY key",
+		plugin, guildID, prefix, prefix, time.Now().Unix(),
+	)
+	return out, err
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func initTestDB(t *testing.T) {
+	t.Helper()
+	err := Init(context.Background(), "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Close() })
+}
+
+func TestKVReplace(t *testing.T) {
+	initTestDB(t)
+
+	for _, value := range []string{`"a"`, `"b"`} {
+		err := KVSet("p", "1", "key", value, time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	value, err := KVGet("p", "1", "key")
+	if err != nil {
+		t.Fatal(err)
+	} else if value != `"b"` {
+		t.Errorf("expected the second value to replace the first, got %s", value)
+	}
+
+	// Entries are scoped by plugin and guild
+	for _, scope := range [][2]string{{"other", "1"}, {"p", "2"}} {
+		_, err = KVGet(scope[0], scope[1], "key")
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected no entry for plugin %q in guild %s, got %v", scope[0], scope[1], err)
+		}
+	}
+
+	err = KVDelete("p", "1", "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = KVGet("p", "1", "key")
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected the entry to be deleted, got %v", err)
+	}
+}
+
+func TestKVExpiry(t *testing.T) {
+	initTestDB(t)
+
+	err := KVSet("p", "1", "expired", `1`, time.Now().Add(-time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = KVSet("p", "1", "fresh", `2`, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = KVGet("p", "1", "expired")
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected the expired entry to be hidden, got %v", err)
+	}
+	if value, err := KVGet("p", "1", "fresh"); err != nil || value != `2` {
+		t.Errorf("expected the unexpired entry, got %q (%v)", value, err)
+	}
+
+	entries, err := KVList("p", "1", "")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(entries) != 1 || entries[0].Key != "fresh" {
+		t.Errorf("expected only the unexpired entry to be listed, got %+v", entries)
+	}
+
+	// Setting any entry removes the plugin's expired entries in the guild
+	var count int
+	err = db.QueryRow("SELECT count(*) FROM plugin_kv WHERE plugin = 'p' AND key = 'expired'").Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	} else if count != 0 {
+		t.Error("expired entry wasn't removed")
+	}
+}
+
+func TestKVList(t *testing.T) {
+	initTestDB(t)
+
+	for _, key := range []string{"user:2", "user:1", "role:1", "user_3"} {
+		err := KVSet("p", "1", key, `true`, time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := KVSet("p", "2", "user:4", `true`, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := KVList("p", "1", "user:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	if len(keys) != 2 || keys[0] != "user:1" || keys[1] != "user:2" {
+		t.Errorf("expected the keys with the prefix in order, got %q", keys)
+	}
+}
@@ -0,0 +1,10 @@
+/* plugin_kv stores key/value pairs for plugins, scoped by plugin and guild. */
+/* expires is a unix timestamp, or NULL if the entry never expires.         */
+CREATE TABLE plugin_kv (
+	plugin   TEXT NOT NULL,
+	guild_id TEXT NOT NULL,
+	key      TEXT NOT NULL,
+	value    TEXT NOT NULL,
+	expires  INTEGER,
+	UNIQUE(plugin, guild_id, key) ON CONFLICT REPLACE
+);
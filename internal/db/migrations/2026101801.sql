@@ -0,0 +1,8 @@
+/* plugin_config stores the values of per-guild plugin settings as JSON. */
+CREATE TABLE plugin_config (
+	plugin   TEXT NOT NULL,
+	guild_id TEXT NOT NULL,
+	key      TEXT NOT NULL,
+	value    TEXT NOT NULL,
+	UNIQUE(plugin, guild_id, key) ON CONFLICT REPLACE
+);
@@ -0,0 +1,7 @@
+/* plugin_migrations records which versioned SQL migrations have been applied for each plugin. */
+CREATE TABLE plugin_migrations (
+	plugin  TEXT NOT NULL,
+	version TEXT NOT NULL,
+	applied INTEGER NOT NULL,
+	UNIQUE(plugin, version)
+);
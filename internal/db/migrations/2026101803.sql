@@ -0,0 +1,13 @@
+/* plugin_migrations gets a guild_id column, so that migrations for plugins with  */
+/* per-guild tables can be tracked separately for each guild. It's empty for      */
+/* migrations that apply to a plugin's global tables.                              */
+CREATE TABLE plugin_migrations_new (
+	plugin   TEXT NOT NULL,
+	guild_id TEXT NOT NULL DEFAULT '',
+	version  TEXT NOT NULL,
+	applied  INTEGER NOT NULL,
+	UNIQUE(plugin, guild_id, version)
+);
+INSERT INTO plugin_migrations_new (plugin, version, applied) SELECT plugin, version, applied FROM plugin_migrations;
+DROP TABLE plugin_migrations;
+ALTER TABLE plugin_migrations_new RENAME TO plugin_migrations;
@@ -0,0 +1,20 @@
+/* plugin_timers stores one-off timers scheduled by plugins with owobot.runAt. */
+/* run_at is a unix timestamp and payload is JSON.                            */
+CREATE TABLE plugin_timers (
+	id       INTEGER PRIMARY KEY AUTOINCREMENT,
+	plugin   TEXT NOT NULL,
+	guild_id TEXT NOT NULL,
+	name     TEXT NOT NULL,
+	run_at   INTEGER NOT NULL,
+	payload  TEXT NOT NULL
+);
+
+/* plugin_schedules stores the last time each cron schedule of a plugin ran in a guild, */
+/* so that runs missed while owobot wasn't running can be caught up on.                 */
+CREATE TABLE plugin_schedules (
+	plugin   TEXT NOT NULL,
+	guild_id TEXT NOT NULL,
+	name     TEXT NOT NULL,
+	last_run INTEGER NOT NULL,
+	UNIQUE(plugin, guild_id, name) ON CONFLICT REPLACE
+);
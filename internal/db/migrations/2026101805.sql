@@ -0,0 +1,2 @@
+/* Add a column to let guilds forward plugin errors to their event log */
+ALTER TABLE guilds ADD COLUMN plugin_error_log BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+/* plugin_approvals stores the capabilities each guild approved when enabling a plugin, */
+/* so that plugins can be disabled when a new version requires more of them.           */
+/* capabilities is JSON.                                                                */
+CREATE TABLE plugin_approvals (
+	plugin       TEXT NOT NULL,
+	guild_id     TEXT NOT NULL,
+	capabilities TEXT NOT NULL,
+	UNIQUE(plugin, guild_id) ON CONFLICT REPLACE
+);
@@ -0,0 +1,54 @@
+/*
+ * owobot - Your server's guardian and entertainer
+ * Copyright (C) 2023 owobot Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// PluginApproval returns the capabilities a guild approved when it enabled a plugin.
+// If the guild hasn't approved any capabilities for the plugin, ok is false.
+func PluginApproval(plugin, guildID string) (caps PluginCapabilities, ok bool, err error) {
+	var data string
+	err = db.QueryRow("SELECT capabilities FROM plugin_approvals WHERE plugin = ? AND guild_id = ?", plugin, guildID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return PluginCapabilities{}, false, nil
+	} else if err != nil {
+		return PluginCapabilities{}, false, err
+	}
+
+	err = json.Unmarshal([]byte(data), &caps)
+	return caps, err == nil, err
+}
+
+// SetPluginApproval stores the capabilities a guild approved for a plugin
+func SetPluginApproval(plugin, guildID string, caps PluginCapabilities) error {
+	data, err := json.Marshal(caps)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(
+		"INSERT INTO plugin_approvals (plugin, guild_id, capabilities) VALUES (?, ?, ?)",
+		plugin, guildID, string(data),
+	)
+	return err
+}
@@ -0,0 +1,53 @@
+/*
+ * owobot - Your server's guardian and entertainer
+ * Copyright (C) 2023 owobot Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package db
+
+// PluginConfig returns the raw values of all the settings a guild
+// has configured for a plugin, keyed by setting name.
+func PluginConfig(plugin, guildID string) (map[string]string, error) {
+	rows, err := db.Query("SELECT key, value FROM plugin_config WHERE plugin = ? AND guild_id = ?", plugin, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]string{}
+	for rows.Next() {
+		var key, value string
+		err = rows.Scan(&key, &value)
+		if err != nil {
+			return nil, err
+		}
+		out[key] = value
+	}
+	return out, rows.Err()
+}
+
+func SetPluginConfig(plugin, guildID, key, value string) error {
+	_, err := db.Exec(
+		"INSERT INTO plugin_config (plugin, guild_id, key, value) VALUES (?, ?, ?, ?)",
+		plugin, guildID, key, value,
+	)
+	return err
+}
+
+func DeletePluginConfig(plugin, guildID, key string) error {
+	_, err := db.Exec("DELETE FROM plugin_config WHERE plugin = ? AND guild_id = ? AND key = ?", plugin, guildID, key)
+	return err
+}
@@ -0,0 +1,55 @@
+/*
+ * owobot - Your server's guardian and entertainer
+ * Copyright (C) 2023 owobot Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package db
+
+import "time"
+
+// AppliedPluginMigrations returns the versions of all the migrations
+// that have been applied for a plugin. For plugins with per-guild tables,
+// guildID selects the guild. Otherwise, it should be empty.
+func AppliedPluginMigrations(plugin, guildID string) ([]string, error) {
+	var out []string
+	err := db.Select(&out, "SELECT version FROM plugin_migrations WHERE plugin = ? AND guild_id = ?", plugin, guildID)
+	return out, err
+}
+
+// ApplyPluginMigration runs a plugin migration and records it as applied.
+// Both happen in a single transaction, so a failed migration has no effect.
+func ApplyPluginMigration(plugin, guildID, version, query string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO plugin_migrations (plugin, guild_id, version, applied) VALUES (?, ?, ?, ?)",
+		plugin, guildID, version, time.Now().Unix(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -18,10 +18,79 @@
 
 package db
 
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
 type PluginInfo struct {
-	Name    string `db:"name"`
-	Version string `db:"version"`
-	Desc    string `db:"description"`
+	Name         string             `db:"name"`
+	Version      string             `db:"version"`
+	Desc         string             `db:"description"`
+	Capabilities PluginCapabilities `db:"-"`
+	Settings     []PluginSetting    `db:"-"`
+	Dependencies []string           `db:"-"`
+	GuildTables  bool               `db:"-"`
+	Intents      discordgo.Intent   `db:"-"`
+}
+
+// PluginSettingType is the type of a per-guild plugin setting
+type PluginSettingType string
+
+const (
+	SettingChannel PluginSettingType = "channel"
+	SettingRole    PluginSettingType = "role"
+	SettingString  PluginSettingType = "string"
+	SettingInt     PluginSettingType = "int"
+	SettingBool    PluginSettingType = "bool"
+)
+
+// PluginSetting describes a per-guild setting declared by a plugin
+type PluginSetting struct {
+	Name    string
+	Desc    string
+	Type    PluginSettingType
+	Default any
+}
+
+// PluginCapabilities describes the privileged APIs a plugin needs access to
+type PluginCapabilities struct {
+	// Hosts is a list of hosts the plugin may make network requests to.
+	// Entries may start with "*." to match any subdomain, and "*" matches any host.
+	Hosts []string
+	// SQL allows the plugin to use the SQL database
+	SQL bool
+	// Tickets allows the plugin to open and close tickets
+	Tickets bool
+	// EventLog allows the plugin to write to the event log
+	EventLog bool
+	// Moderation allows the plugin to kick, ban, time out, and change the roles of members
+	Moderation bool
+}
+
+// AllowsHost checks whether the capabilities allow network requests to the given host
+func (pc PluginCapabilities) AllowsHost(host string) bool {
+	return MatchHost(pc.Hosts, host)
+}
+
+// MatchHost checks whether host matches any of the given patterns.
+// Patterns may start with "*." to match any subdomain, and "*" matches any host.
+func MatchHost(patterns []string, host string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range patterns {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" || allowed == host {
+			return true
+		}
+
+		// The suffix has to match at a label boundary, so that
+		// "*.example.com" doesn't match "notexample.com".
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok && strings.HasSuffix(host, "."+suffix) {
+			return true
+		}
+	}
+	return false
 }
 
 func (pi PluginInfo) IsValid() bool {
@@ -0,0 +1,30 @@
+package db
+
+import "testing"
+
+func TestMatchHost(t *testing.T) {
+	cases := []struct {
+		patterns []string
+		host     string
+		match    bool
+	}{
+		{[]string{"example.com"}, "example.com", true},
+		{[]string{"Example.com"}, "EXAMPLE.COM", true},
+		{[]string{"example.com"}, "api.example.com", false},
+		{[]string{"*.example.com"}, "api.example.com", true},
+		{[]string{"*.example.com"}, "a.b.example.com", true},
+		{[]string{"*.example.com"}, "example.com", false},
+		{[]string{"*.example.com"}, "notexample.com", false},
+		{[]string{"*example.com"}, "notexample.com", false},
+		{[]string{"*example.com"}, "api.example.com", false},
+		{[]string{"*"}, "anything.org", true},
+		{[]string{"a.org", "*.example.com"}, "x.example.com", true},
+		{nil, "example.com", false},
+	}
+
+	for _, c := range cases {
+		if got := MatchHost(c.patterns, c.host); got != c.match {
+			t.Errorf("MatchHost(%q, %q) = %t, expected %t", c.patterns, c.host, got, c.match)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/*
+ * owobot - Your server's guardian and entertainer
+ * Copyright (C) 2023 owobot Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type PluginTimer struct {
+	ID      int64  `db:"id"`
+	Plugin  string `db:"plugin"`
+	GuildID string `db:"guild_id"`
+	Name    string `db:"name"`
+	RunAt   int64  `db:"run_at"`
+	Payload string `db:"payload"`
+}
+
+// AddPluginTimer stores a new timer for a plugin and returns its ID
+func AddPluginTimer(plugin, guildID, name string, runAt time.Time, payload string) (int64, error) {
+	res, err := db.Exec(
+		"INSERT INTO plugin_timers (plugin, guild_id, name, run_at, payload) VALUES (?, ?, ?, ?, ?)",
+		plugin, guildID, name, runAt.Unix(), payload,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// DuePluginTimers returns all the timers that should have run by now
+func DuePluginTimers(now time.Time) ([]PluginTimer, error) {
+	var out []PluginTimer
+	err := db.Select(&out, "SELECT * FROM plugin_timers WHERE run_at <= ? ORDER BY run_at", now.Unix())
+	return out, err
+}
+
+// DeletePluginTimer removes a timer, returning whether it existed
+func DeletePluginTimer(plugin string, id int64) (bool, error) {
+	res, err := db.Exec("DELETE FROM plugin_timers WHERE plugin = ? AND id = ?", plugin, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// PluginScheduleLastRun returns the last time a plugin's schedule ran in a guild.
+// If it has never run, the zero time is returned.
+func PluginScheduleLastRun(plugin, guildID, name string) (time.Time, error) {
+	var lastRun int64
+	err := db.QueryRow(
+		"SELECT last_run FROM plugin_schedules WHERE plugin = ? AND guild_id = ? AND name = ?",
+		plugin, guildID, name,
+	).Scan(&lastRun)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(lastRun, 0), nil
+}
+
+// SetPluginScheduleLastRun records the last time a plugin's schedule ran in a guild
+func SetPluginScheduleLastRun(plugin, guildID, name string, lastRun time.Time) error {
+	_, err := db.Exec(
+		"INSERT INTO plugin_schedules (plugin, guild_id, name, last_run) VALUES (?, ?, ?, ?)",
+		plugin, guildID, name, lastRun.Unix(),
+	)
+	return err
+}
+
+// DeletePluginSchedules removes all of a plugin's timers and schedule
+// records in a guild. It's used when the plugin is disabled there.
+func DeletePluginSchedules(plugin, guildID string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM plugin_timers WHERE plugin = ? AND guild_id = ?", plugin, guildID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM plugin_schedules WHERE plugin = ? AND guild_id = ?", plugin, guildID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
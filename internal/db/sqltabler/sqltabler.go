@@ -2,14 +2,40 @@ package sqltabler
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 
 	sqlparser "github.com/rqlite/sql"
 )
 
-// Modify adds a prefix and suffix to every table name found in stmt.
-func Modify(stmt, prefix, suffix string) (string, error) {
+// ErrUnsupported is returned when a statement contains something
+// that can't be safely rewritten.
+var ErrUnsupported = errors.New("unsupported SQL")
+
+// errReturning is returned for UPDATE and DELETE statements with RETURNING clauses,
+// because the parser drops them when converting the statements back to SQL.
+var errReturning = fmt.Errorf("%w: RETURNING is only supported in INSERT statements", ErrUnsupported)
+
+// forbiddenFuncs contains the names of SQL functions that can't be called
+// because they could be used to access things outside the rewritten tables.
+var forbiddenFuncs = map[string]bool{
+	"load_extension": true,
+}
+
+// Modify adds a prefix and suffix to every table, view, trigger, and index name
+// found in stmt. It returns an error wrapping [ErrUnsupported] if stmt contains
+// any statement or reference that it can't rewrite.
+func Modify(stmt, prefix, suffix string) (out string, err error) {
+	// The parser panics on some statements it can parse but not convert
+	// back to SQL. Since statements can come from plugins, that can't be
+	// allowed to crash the bot.
+	defer func() {
+		if r := recover(); r != nil {
+			out, err = "", fmt.Errorf("%w: %v", ErrUnsupported, r)
+		}
+	}()
+
 	parser := sqlparser.NewParser(strings.NewReader(stmt))
 	sb := strings.Builder{}
 	for {
@@ -19,138 +45,456 @@ func Modify(stmt, prefix, suffix string) (string, error) {
 		} else if err != nil {
 			return "", err
 		}
-		modify(s, prefix, suffix)
+
+		r := rewriter{aliases: map[string]bool{}}
+		err = r.statement(s)
+		if err != nil {
+			return "", err
+		}
+		err = r.apply(prefix, suffix)
+		if err != nil {
+			return "", err
+		}
+
 		sb.WriteString(s.String())
 		sb.WriteByte(';')
 	}
 	return sb.String(), nil
 }
 
-// modify changes all the table, view, trigger, and index names in a single statement
-func modify(stmt any, prefix, suffix string) {
+// rewriter walks a statement and collects all the identifiers
+// that refer to tables, so that they can be renamed.
+type rewriter struct {
+	// names contains identifiers that name tables, views, triggers, or indexes
+	names []*sqlparser.Ident
+	// refs contains the table identifiers of qualified column references.
+	// These may also refer to aliases, which aren't renamed.
+	refs []*sqlparser.Ident
+	// aliases contains the lowercase names of all the aliases declared in the statement
+	aliases map[string]bool
+}
+
+// apply renames all the collected identifiers
+func (r *rewriter) apply(prefix, suffix string) error {
+	for _, name := range r.names {
+		if err := checkName(name); err != nil {
+			return err
+		}
+	}
+
+	for _, ref := range r.refs {
+		if r.aliases[strings.ToLower(ref.Name)] {
+			continue
+		}
+		if err := checkName(ref); err != nil {
+			return err
+		}
+		ref.Name = prefix + ref.Name + suffix
+	}
+
+	for _, name := range r.names {
+		name.Name = prefix + name.Name + suffix
+	}
+
+	return nil
+}
+
+// checkName returns an error if name refers to one of sqlite's internal tables
+func checkName(name *sqlparser.Ident) error {
+	if strings.HasPrefix(strings.ToLower(name.Name), "sqlite_") {
+		return fmt.Errorf("%w: reference to internal table %q", ErrUnsupported, name.Name)
+	}
+	return nil
+}
+
+func (r *rewriter) name(ident *sqlparser.Ident) {
+	if ident != nil {
+		r.names = append(r.names, ident)
+	}
+}
+
+func (r *rewriter) alias(ident *sqlparser.Ident) {
+	if ident != nil {
+		r.aliases[strings.ToLower(ident.Name)] = true
+	}
+}
+
+// statement collects the table identifiers in a single statement
+func (r *rewriter) statement(stmt sqlparser.Statement) error {
 	switch stmt := stmt.(type) {
 	case *sqlparser.SelectStatement:
-		modifySource(stmt.Source, prefix, suffix)
-		modify(stmt.WhereExpr, prefix, suffix)
+		return r.selectStmt(stmt)
 	case *sqlparser.InsertStatement:
-		stmt.Table.Name = prefix + stmt.Table.Name + suffix
+		if err := r.with(stmt.WithClause); err != nil {
+			return err
+		}
+		r.name(stmt.Table)
+		r.alias(stmt.Alias)
+		for _, list := range stmt.ValueLists {
+			if err := r.expr(list); err != nil {
+				return err
+			}
+		}
 		if stmt.Select != nil {
-			modify(stmt.Select, prefix, suffix)
+			if err := r.selectStmt(stmt.Select); err != nil {
+				return err
+			}
+		}
+		if uc := stmt.UpsertClause; uc != nil {
+			if err := r.indexedColumns(uc.Columns); err != nil {
+				return err
+			}
+			if err := r.exprs(uc.WhereExpr, uc.UpdateWhereExpr); err != nil {
+				return err
+			}
+			if err := r.assignments(uc.Assignments); err != nil {
+				return err
+			}
 		}
+		return r.returning(stmt.ReturningClause)
 	case *sqlparser.UpdateStatement:
-		stmt.Table.Name.Name = prefix + stmt.Table.Name.Name + suffix
-		for _, assignment := range stmt.Assignments {
-			modify(assignment, prefix, suffix)
+		if err := r.with(stmt.WithClause); err != nil {
+			return err
 		}
-	case *sqlparser.CreateTableStatement:
-		stmt.Name.Name = prefix + stmt.Name.Name + suffix
-		if stmt.Select != nil {
-			modify(stmt.Select, prefix, suffix)
+		if err := r.source(stmt.Table); err != nil {
+			return err
 		}
-		for _, col := range stmt.Columns {
-			modify(col, prefix, suffix)
+		if err := r.assignments(stmt.Assignments); err != nil {
+			return err
 		}
-		for _, constraint := range stmt.Constraints {
-			modify(constraint, prefix, suffix)
+		if stmt.ReturningClause != nil {
+			return errReturning
 		}
-	case *sqlparser.CreateViewStatement:
-		stmt.Name.Name = prefix + stmt.Name.Name + suffix
-		if stmt.Select != nil {
-			modify(stmt.Select, prefix, suffix)
+		return r.expr(stmt.WhereExpr)
+	case *sqlparser.DeleteStatement:
+		if err := r.with(stmt.WithClause); err != nil {
+			return err
 		}
-	case *sqlparser.AlterTableStatement:
-		stmt.Name.Name = prefix + stmt.Name.Name + suffix
-		if stmt.NewName != nil {
-			stmt.NewName.Name = prefix + stmt.NewName.Name + suffix
+		if err := r.source(stmt.Table); err != nil {
+			return err
 		}
-		if stmt.ColumnDef != nil {
-			modify(stmt.ColumnDef, prefix, suffix)
+		if err := r.exprs(stmt.WhereExpr, stmt.LimitExpr, stmt.OffsetExpr); err != nil {
+			return err
 		}
-	case *sqlparser.Call:
-		for _, arg := range stmt.Args {
-			modify(arg, prefix, suffix)
+		if stmt.ReturningClause != nil {
+			return errReturning
 		}
-	case *sqlparser.FilterClause:
-		modify(stmt.X, prefix, suffix)
-	case *sqlparser.DeleteStatement:
-		stmt.Table.Name.Name = prefix + stmt.Table.Name.Name + suffix
-		if stmt.WhereExpr != nil {
-			modify(stmt.WhereExpr, prefix, suffix)
+		return r.orderingTerms(stmt.OrderingTerms)
+	case *sqlparser.CreateTableStatement:
+		r.name(stmt.Name)
+		for _, col := range stmt.Columns {
+			if err := r.constraints(col.Constraints); err != nil {
+				return err
+			}
 		}
-	case *sqlparser.AnalyzeStatement:
-		stmt.Name.Name = prefix + stmt.Name.Name + suffix
-	case *sqlparser.ExplainStatement:
-		modify(stmt.Stmt, prefix, suffix)
+		if err := r.constraints(stmt.Constraints); err != nil {
+			return err
+		}
+		if stmt.Select != nil {
+			return r.selectStmt(stmt.Select)
+		}
+		return nil
+	case *sqlparser.CreateViewStatement:
+		r.name(stmt.Name)
+		if stmt.Select != nil {
+			return r.selectStmt(stmt.Select)
+		}
+		return nil
 	case *sqlparser.CreateIndexStatement:
-		stmt.Name.Name = prefix + stmt.Name.Name + suffix
-		stmt.Table.Name = prefix + stmt.Table.Name + suffix
+		r.name(stmt.Name)
+		r.name(stmt.Table)
+		if err := r.indexedColumns(stmt.Columns); err != nil {
+			return err
+		}
+		return r.expr(stmt.WhereExpr)
 	case *sqlparser.CreateTriggerStatement:
-		stmt.Name.Name = prefix + stmt.Name.Name + suffix
-		stmt.Table.Name = prefix + stmt.Table.Name + suffix
-		if stmt.WhenExpr != nil {
-			modify(stmt.WhenExpr, prefix, suffix)
+		r.name(stmt.Name)
+		r.name(stmt.Table)
+		// NEW and OLD refer to the rows that triggered the trigger
+		r.aliases["new"] = true
+		r.aliases["old"] = true
+		if err := r.expr(stmt.WhenExpr); err != nil {
+			return err
 		}
-		for _, istmt := range stmt.Body {
-			modify(istmt, prefix, suffix)
+		for _, bodyStmt := range stmt.Body {
+			if err := r.statement(bodyStmt); err != nil {
+				return err
+			}
 		}
-	case *sqlparser.CTE:
-		stmt.TableName.Name = prefix + stmt.TableName.Name + suffix
-		if stmt.Select != nil {
-			modify(stmt.Select, prefix, suffix)
+		return nil
+	case *sqlparser.AlterTableStatement:
+		r.name(stmt.Name)
+		r.name(stmt.NewName)
+		if stmt.ColumnDef != nil {
+			return r.constraints(stmt.ColumnDef.Constraints)
 		}
+		return nil
 	case *sqlparser.DropTableStatement:
-		stmt.Name.Name = prefix + stmt.Name.Name + suffix
+		r.name(stmt.Name)
+		return nil
 	case *sqlparser.DropViewStatement:
-		stmt.Name.Name = prefix + stmt.Name.Name + suffix
+		r.name(stmt.Name)
+		return nil
 	case *sqlparser.DropIndexStatement:
-		stmt.Name.Name = prefix + stmt.Name.Name + suffix
+		r.name(stmt.Name)
+		return nil
 	case *sqlparser.DropTriggerStatement:
-		stmt.Name.Name = prefix + stmt.Name.Name + suffix
-	case *sqlparser.ForeignKeyConstraint:
-		stmt.ForeignTable.Name = prefix + stmt.ForeignTable.Name + suffix
-	case *sqlparser.OnConstraint:
-		modify(stmt.X, prefix, suffix)
-	case *sqlparser.ExprList:
-		for _, expr := range stmt.Exprs {
-			modify(expr, prefix, suffix)
+		r.name(stmt.Name)
+		return nil
+	case *sqlparser.AnalyzeStatement:
+		r.name(stmt.Name)
+		return nil
+	case *sqlparser.ExplainStatement:
+		return r.statement(stmt.Stmt)
+	case *sqlparser.BeginStatement, *sqlparser.CommitStatement, *sqlparser.RollbackStatement,
+		*sqlparser.SavepointStatement, *sqlparser.ReleaseStatement:
+		return fmt.Errorf("%w: transaction control statements aren't allowed", ErrUnsupported)
+	default:
+		return fmt.Errorf("%w: statement type %T", ErrUnsupported, stmt)
+	}
+}
+
+func (r *rewriter) selectStmt(stmt *sqlparser.SelectStatement) error {
+	if err := r.with(stmt.WithClause); err != nil {
+		return err
+	}
+	for _, list := range stmt.ValueLists {
+		if err := r.expr(list); err != nil {
+			return err
+		}
+	}
+	for _, col := range stmt.Columns {
+		if err := r.expr(col.Expr); err != nil {
+			return err
 		}
+	}
+	if stmt.Source != nil {
+		if err := r.source(stmt.Source); err != nil {
+			return err
+		}
+	}
+	if err := r.exprs(stmt.WhereExpr, stmt.HavingExpr, stmt.LimitExpr, stmt.OffsetExpr); err != nil {
+		return err
+	}
+	if err := r.exprs(stmt.GroupByExprs...); err != nil {
+		return err
+	}
+	for _, window := range stmt.Windows {
+		if err := r.windowDefinition(window.Definition); err != nil {
+			return err
+		}
+	}
+	if err := r.orderingTerms(stmt.OrderingTerms); err != nil {
+		return err
+	}
+	if stmt.Compound != nil {
+		return r.selectStmt(stmt.Compound)
+	}
+	return nil
+}
+
+// with collects the names of common table expressions. They're renamed along
+// with the tables, so references to them stay consistent.
+func (r *rewriter) with(wc *sqlparser.WithClause) error {
+	if wc == nil {
+		return nil
+	}
+	for _, cte := range wc.CTEs {
+		r.name(cte.TableName)
+		if cte.Select != nil {
+			if err := r.selectStmt(cte.Select); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *rewriter) source(src sqlparser.Source) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case *sqlparser.QualifiedTableName:
+		r.name(src.Name)
+		r.alias(src.Alias)
+		// Indexes are renamed when they're created, so INDEXED BY has to match
+		r.name(src.Index)
+		return nil
+	case *sqlparser.ParenSource:
+		r.alias(src.Alias)
+		return r.source(src.X)
+	case *sqlparser.JoinClause:
+		if err := r.source(src.X); err != nil {
+			return err
+		}
+		if err := r.source(src.Y); err != nil {
+			return err
+		}
+		if on, ok := src.Constraint.(*sqlparser.OnConstraint); ok {
+			return r.expr(on.X)
+		}
+		return nil
+	case *sqlparser.SelectStatement:
+		return r.selectStmt(src)
+	default:
+		return fmt.Errorf("%w: source type %T", ErrUnsupported, src)
+	}
+}
+
+func (r *rewriter) exprs(exprs ...sqlparser.Expr) error {
+	for _, expr := range exprs {
+		if err := r.expr(expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rewriter) expr(expr sqlparser.Expr) error {
+	switch expr := expr.(type) {
+	case nil:
+		return nil
+	case *sqlparser.Ident, *sqlparser.StringLit, *sqlparser.BlobLit, *sqlparser.NumberLit,
+		*sqlparser.BoolLit, *sqlparser.NullLit, *sqlparser.TimestampLit, *sqlparser.BindExpr,
+		*sqlparser.Raise:
+		return nil
+	case *sqlparser.QualifiedRef:
+		if expr.Table != nil {
+			r.refs = append(r.refs, expr.Table)
+		}
+		return nil
+	case *sqlparser.ParenExpr:
+		return r.expr(expr.X)
 	case *sqlparser.UnaryExpr:
-		modify(stmt.X, prefix, suffix)
+		return r.expr(expr.X)
 	case *sqlparser.BinaryExpr:
-		modify(stmt.X, prefix, suffix)
-		modify(stmt.Y, prefix, suffix)
-	case *sqlparser.ParenExpr:
-		modify(stmt.X, prefix, suffix)
+		// The parser panics when converting ESCAPE expressions back to SQL
+		if expr.Op == sqlparser.ESCAPE {
+			return fmt.Errorf("%w: ESCAPE clauses", ErrUnsupported)
+		}
+		return r.exprs(expr.X, expr.Y)
 	case *sqlparser.CastExpr:
-		modify(stmt.X, prefix, suffix)
-	case *sqlparser.OrderingTerm:
-		modify(stmt.X, prefix, suffix)
-	case *sqlparser.Assignment:
-		modify(stmt.Expr, prefix, suffix)
-	case *sqlparser.ColumnDefinition:
-		for _, constraint := range stmt.Constraints {
-			modify(constraint, prefix, suffix)
+		return r.expr(expr.X)
+	case *sqlparser.Range:
+		return r.exprs(expr.X, expr.Y)
+	case *sqlparser.ExprList:
+		return r.exprs(expr.Exprs...)
+	case *sqlparser.CaseExpr:
+		if err := r.exprs(expr.Operand, expr.ElseExpr); err != nil {
+			return err
 		}
-	case *sqlparser.QualifiedRef:
-		if stmt.Table != nil {
-			stmt.Table.Name = prefix + stmt.Table.Name + suffix
+		for _, block := range expr.Blocks {
+			if err := r.exprs(block.Condition, block.Body); err != nil {
+				return err
+			}
 		}
-	case *sqlparser.CaseExpr:
-		modify(stmt.ElseExpr, prefix, suffix)
-		for _, block := range stmt.Blocks {
-			modify(block.Condition, prefix, suffix)
-			modify(block.Body, prefix, suffix)
+		return nil
+	case *sqlparser.Exists:
+		if expr.Select != nil {
+			return r.selectStmt(expr.Select)
+		}
+		return nil
+	case *sqlparser.Call:
+		if forbiddenFuncs[strings.ToLower(expr.Name.Name)] {
+			return fmt.Errorf("%w: function %q isn't allowed", ErrUnsupported, expr.Name.Name)
 		}
+		if err := r.exprs(expr.Args...); err != nil {
+			return err
+		}
+		if expr.Filter != nil {
+			if err := r.expr(expr.Filter.X); err != nil {
+				return err
+			}
+		}
+		if expr.Over != nil {
+			return r.windowDefinition(expr.Over.Definition)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: expression type %T", ErrUnsupported, expr)
 	}
 }
 
-func modifySource(source sqlparser.Source, prefix, suffix string) {
-	switch source := source.(type) {
-	case *sqlparser.QualifiedTableName:
-		source.Name.Name = prefix + source.Name.Name + suffix
-	case *sqlparser.JoinClause:
-		modifySource(source.X, prefix, suffix)
-		modifySource(source.Y, prefix, suffix)
-		modify(source.Constraint, prefix, suffix)
+func (r *rewriter) windowDefinition(wd *sqlparser.WindowDefinition) error {
+	if wd == nil {
+		return nil
+	}
+	if err := r.exprs(wd.Partitions...); err != nil {
+		return err
+	}
+	if err := r.orderingTerms(wd.OrderingTerms); err != nil {
+		return err
+	}
+	if wd.Frame != nil {
+		return r.exprs(wd.Frame.X, wd.Frame.Y)
+	}
+	return nil
+}
+
+func (r *rewriter) orderingTerms(terms []*sqlparser.OrderingTerm) error {
+	for _, term := range terms {
+		if err := r.expr(term.X); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rewriter) indexedColumns(cols []*sqlparser.IndexedColumn) error {
+	for _, col := range cols {
+		if err := r.expr(col.X); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rewriter) assignments(assignments []*sqlparser.Assignment) error {
+	for _, assignment := range assignments {
+		if err := r.expr(assignment.Expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rewriter) returning(rc *sqlparser.ReturningClause) error {
+	if rc == nil {
+		return nil
+	}
+	for _, col := range rc.Columns {
+		if err := r.expr(col.Expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rewriter) constraints(constraints []sqlparser.Constraint) error {
+	for _, constraint := range constraints {
+		switch constraint := constraint.(type) {
+		case *sqlparser.PrimaryKeyConstraint, *sqlparser.NotNullConstraint, *sqlparser.CollateConstraint:
+		case *sqlparser.UniqueConstraint:
+			if err := r.indexedColumns(constraint.Columns); err != nil {
+				return err
+			}
+		case *sqlparser.CheckConstraint:
+			if err := r.expr(constraint.Expr); err != nil {
+				return err
+			}
+		case *sqlparser.DefaultConstraint:
+			if err := r.expr(constraint.Expr); err != nil {
+				return err
+			}
+		case *sqlparser.GeneratedConstraint:
+			if err := r.expr(constraint.Expr); err != nil {
+				return err
+			}
+		case *sqlparser.ForeignKeyConstraint:
+			r.name(constraint.ForeignTable)
+		default:
+			return fmt.Errorf("%w: constraint type %T", ErrUnsupported, constraint)
+		}
 	}
+	return nil
 }
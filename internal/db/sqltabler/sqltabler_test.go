@@ -0,0 +1,332 @@
+package sqltabler
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestModify(t *testing.T) {
+	type testCase struct {
+		name  string
+		query string
+		// expected is the expected output. It's ignored if err is set.
+		expected string
+		// err is the expected error. If it's nil, but parseErr
+		// is true, any error is expected.
+		err      error
+		parseErr bool
+	}
+
+	cases := []testCase{
+		{
+			name:     "select",
+			query:    "SELECT * FROM counts WHERE user = ?",
+			expected: `SELECT * FROM "p_counts_s" WHERE "user" = ?;`,
+		},
+		{
+			name:     "multiple statements",
+			query:    "SELECT 1 FROM a; SELECT 2 FROM b",
+			expected: `SELECT 1 FROM "p_a_s";SELECT 2 FROM "p_b_s";`,
+		},
+		{
+			name:     "join",
+			query:    "SELECT a.x, b.y FROM a JOIN b ON a.id = b.id",
+			expected: `SELECT "p_a_s"."x", "p_b_s"."y" FROM "p_a_s" JOIN "p_b_s" ON "p_a_s"."id" = "p_b_s"."id";`,
+		},
+		{
+			name:     "join using",
+			query:    "SELECT * FROM a JOIN b USING (id)",
+			expected: `SELECT * FROM "p_a_s" JOIN "p_b_s" USING ("id");`,
+		},
+		{
+			name:     "table alias",
+			query:    "SELECT x.id FROM a AS x WHERE x.id > 1",
+			expected: `SELECT "x"."id" FROM "p_a_s" AS "x" WHERE "x"."id" > 1;`,
+		},
+		{
+			name:     "alias declared after use is case insensitive",
+			query:    `SELECT X.id FROM a AS "x"`,
+			expected: `SELECT "X"."id" FROM "p_a_s" AS "x";`,
+		},
+		{
+			name:     "subquery source",
+			query:    "SELECT s.id FROM (SELECT id FROM guilds) AS s",
+			expected: `SELECT "s"."id" FROM (SELECT "id" FROM "p_guilds_s") AS "s";`,
+		},
+		{
+			name:     "exists",
+			query:    "SELECT * FROM a WHERE EXISTS (SELECT 1 FROM guilds)",
+			expected: `SELECT * FROM "p_a_s" WHERE EXISTS (SELECT 1 FROM "p_guilds_s");`,
+		},
+		{
+			name:     "not exists in nested expression",
+			query:    "SELECT * FROM a WHERE x = 1 AND (NOT EXISTS (SELECT 1 FROM tickets))",
+			expected: `SELECT * FROM "p_a_s" WHERE "x" = 1 AND (NOT EXISTS (SELECT 1 FROM "p_tickets_s"));`,
+		},
+		{
+			name:     "exists in result column",
+			query:    "SELECT EXISTS (SELECT 1 FROM votes) AS v",
+			expected: `SELECT EXISTS (SELECT 1 FROM "p_votes_s") AS "v";`,
+		},
+		{
+			name:     "exists in case expression",
+			query:    "SELECT CASE WHEN EXISTS (SELECT 1 FROM votes) THEN 1 ELSE 0 END FROM a",
+			expected: `SELECT CASE WHEN EXISTS (SELECT 1 FROM "p_votes_s") THEN 1 ELSE 0 END FROM "p_a_s";`,
+		},
+		{
+			name:     "exists in function argument",
+			query:    "SELECT coalesce(EXISTS (SELECT 1 FROM votes), 0) FROM a",
+			expected: `SELECT coalesce(EXISTS (SELECT 1 FROM "p_votes_s"), 0) FROM "p_a_s";`,
+		},
+		{
+			name:     "exists in having and group by",
+			query:    "SELECT x FROM a GROUP BY x HAVING EXISTS (SELECT 1 FROM votes)",
+			expected: `SELECT "x" FROM "p_a_s" GROUP BY "x" HAVING EXISTS (SELECT 1 FROM "p_votes_s");`,
+		},
+		{
+			name:     "exists in order by",
+			query:    "SELECT x FROM a ORDER BY EXISTS (SELECT 1 FROM votes)",
+			expected: `SELECT "x" FROM "p_a_s" ORDER BY EXISTS (SELECT 1 FROM "p_votes_s");`,
+		},
+		{
+			name:     "window function",
+			query:    "SELECT row_number() OVER (PARTITION BY a.x ORDER BY a.y) FROM a",
+			expected: `SELECT row_number() OVER (PARTITION BY "p_a_s"."x" ORDER BY "p_a_s"."y") FROM "p_a_s";`,
+		},
+		{
+			name:     "filter clause",
+			query:    "SELECT count(*) FILTER (WHERE EXISTS (SELECT 1 FROM votes)) FROM a",
+			expected: `SELECT count(*) FILTER (WHERE EXISTS (SELECT 1 FROM "p_votes_s")) FROM "p_a_s";`,
+		},
+		{
+			name:     "compound select",
+			query:    "SELECT id FROM a UNION SELECT id FROM guilds",
+			expected: `SELECT "id" FROM "p_a_s" UNION SELECT "id" FROM "p_guilds_s";`,
+		},
+		{
+			name:     "common table expression",
+			query:    "WITH c AS (SELECT * FROM guilds) SELECT c.id FROM c",
+			expected: `WITH "p_c_s" AS (SELECT * FROM "p_guilds_s") SELECT "p_c_s"."id" FROM "p_c_s";`,
+		},
+		{
+			name:     "indexed by",
+			query:    "SELECT * FROM a INDEXED BY idx",
+			expected: `SELECT * FROM "p_a_s" INDEXED BY "p_idx_s";`,
+		},
+		{
+			name:     "insert",
+			query:    "INSERT INTO a (x, y) VALUES (1, 2)",
+			expected: `INSERT INTO "p_a_s" ("x", "y") VALUES (1, 2);`,
+		},
+		{
+			name:     "insert select",
+			query:    "INSERT INTO a SELECT * FROM guilds",
+			expected: `INSERT INTO "p_a_s" SELECT * FROM "p_guilds_s";`,
+		},
+		{
+			name:     "insert with exists in values",
+			query:    "INSERT INTO a VALUES (EXISTS (SELECT 1 FROM guilds))",
+			expected: `INSERT INTO "p_a_s" VALUES (EXISTS (SELECT 1 FROM "p_guilds_s"));`,
+		},
+		{
+			name:     "upsert",
+			query:    "INSERT INTO a (x) VALUES (1) ON CONFLICT (x) DO UPDATE SET y = EXISTS (SELECT 1 FROM guilds)",
+			expected: `INSERT INTO "p_a_s" ("x") VALUES (1) ON CONFLICT ("x") DO UPDATE SET "y" = EXISTS (SELECT 1 FROM "p_guilds_s");`,
+		},
+		{
+			name:     "insert returning",
+			query:    "INSERT INTO a (x) VALUES (1) RETURNING EXISTS (SELECT 1 FROM guilds)",
+			expected: `INSERT INTO "p_a_s" ("x") VALUES (1) RETURNING EXISTS (SELECT 1 FROM "p_guilds_s");`,
+		},
+		{
+			name:     "update",
+			query:    "UPDATE a SET x = 1 WHERE EXISTS (SELECT 1 FROM guilds)",
+			expected: `UPDATE "p_a_s" SET "x" = 1 WHERE EXISTS (SELECT 1 FROM "p_guilds_s");`,
+		},
+		{
+			name:     "delete",
+			query:    "DELETE FROM a WHERE EXISTS (SELECT 1 FROM guilds)",
+			expected: `DELETE FROM "p_a_s" WHERE EXISTS (SELECT 1 FROM "p_guilds_s");`,
+		},
+		{
+			name:     "with delete",
+			query:    "WITH c AS (SELECT id FROM guilds) DELETE FROM a WHERE EXISTS (SELECT 1 FROM c)",
+			expected: `WITH "p_c_s" AS (SELECT "id" FROM "p_guilds_s") DELETE FROM "p_a_s" WHERE EXISTS (SELECT 1 FROM "p_c_s");`,
+		},
+		{
+			name:     "create table",
+			query:    "CREATE TABLE a (id INT PRIMARY KEY, b INT REFERENCES b(id), c INT CHECK (EXISTS (SELECT 1 FROM guilds)))",
+			expected: `CREATE TABLE "p_a_s" ("id" INT PRIMARY KEY, "b" INT REFERENCES "p_b_s" ("id"), "c" INT CHECK (EXISTS (SELECT 1 FROM "p_guilds_s")));`,
+		},
+		{
+			name:     "create table foreign key constraint",
+			query:    "CREATE TABLE a (b INT, FOREIGN KEY (b) REFERENCES guilds (id))",
+			expected: `CREATE TABLE "p_a_s" ("b" INT, FOREIGN KEY ("b") REFERENCES "p_guilds_s" ("id"));`,
+		},
+		{
+			name:     "create table as select",
+			query:    "CREATE TABLE a AS SELECT * FROM guilds",
+			expected: `CREATE TABLE "p_a_s" AS SELECT * FROM "p_guilds_s";`,
+		},
+		{
+			name:     "create view",
+			query:    "CREATE VIEW v AS SELECT * FROM guilds",
+			expected: `CREATE VIEW "p_v_s" AS SELECT * FROM "p_guilds_s";`,
+		},
+		{
+			name:     "create index",
+			query:    "CREATE INDEX idx ON a (x)",
+			expected: `CREATE INDEX "p_idx_s" ON "p_a_s" ("x");`,
+		},
+		{
+			name:     "create trigger",
+			query:    "CREATE TRIGGER t AFTER INSERT ON a BEGIN INSERT INTO b VALUES (NEW.x); UPDATE c SET y = OLD.y; END",
+			expected: `CREATE TRIGGER "p_t_s" AFTER INSERT ON "p_a_s" BEGIN INSERT INTO "p_b_s" VALUES ("NEW"."x"); UPDATE "p_c_s" SET "y" = "OLD"."y"; END;`,
+		},
+		{
+			name:     "alter table",
+			query:    "ALTER TABLE a RENAME TO b",
+			expected: `ALTER TABLE "p_a_s" RENAME TO "p_b_s";`,
+		},
+		{
+			name:     "alter table add column",
+			query:    "ALTER TABLE a ADD COLUMN b INT REFERENCES guilds(id)",
+			expected: `ALTER TABLE "p_a_s" ADD COLUMN "b" INT REFERENCES "p_guilds_s" ("id");`,
+		},
+		{
+			name:     "drop",
+			query:    "DROP TABLE a; DROP VIEW v; DROP INDEX i; DROP TRIGGER t",
+			expected: `DROP TABLE "p_a_s";DROP VIEW "p_v_s";DROP INDEX "p_i_s";DROP TRIGGER "p_t_s";`,
+		},
+		{
+			name:     "analyze",
+			query:    "ANALYZE a",
+			expected: `ANALYZE "p_a_s";`,
+		},
+		{
+			name:     "explain",
+			query:    "EXPLAIN QUERY PLAN SELECT * FROM guilds",
+			expected: `EXPLAIN QUERY PLAN SELECT * FROM "p_guilds_s";`,
+		},
+		{
+			name:  "update returning",
+			query: "UPDATE a SET x = 1 RETURNING x",
+			err:   ErrUnsupported,
+		},
+		{
+			name:  "delete returning",
+			query: "DELETE FROM a RETURNING *",
+			err:   ErrUnsupported,
+		},
+		{
+			name:  "sqlite_master",
+			query: "SELECT * FROM sqlite_master",
+			err:   ErrUnsupported,
+		},
+		{
+			name:  "sqlite_schema in exists",
+			query: "SELECT * FROM a WHERE EXISTS (SELECT 1 FROM SQLITE_SCHEMA)",
+			err:   ErrUnsupported,
+		},
+		{
+			name:  "sqlite_sequence qualified ref",
+			query: "SELECT sqlite_sequence.seq FROM a",
+			err:   ErrUnsupported,
+		},
+		{
+			name:  "load_extension",
+			query: "SELECT load_extension('evil.so')",
+			err:   ErrUnsupported,
+		},
+		{
+			name:  "like escape",
+			query: "SELECT * FROM a WHERE x LIKE '%!_%' ESCAPE '!'",
+			err:   ErrUnsupported,
+		},
+		{
+			name:  "not like escape in subquery",
+			query: "SELECT * FROM a WHERE EXISTS (SELECT 1 FROM b WHERE y NOT LIKE 'a' ESCAPE 'b')",
+			err:   ErrUnsupported,
+		},
+		{
+			name:  "begin",
+			query: "BEGIN",
+			err:   ErrUnsupported,
+		},
+		{
+			name:  "commit",
+			query: "SELECT 1 FROM a; COMMIT",
+			err:   ErrUnsupported,
+		},
+		{
+			name:  "savepoint",
+			query: "SAVEPOINT s",
+			err:   ErrUnsupported,
+		},
+		{
+			name:     "schema qualified table",
+			query:    "SELECT * FROM main.guilds",
+			parseErr: true,
+		},
+		{
+			name:     "schema qualified insert",
+			query:    "INSERT INTO main.guilds VALUES (1)",
+			parseErr: true,
+		},
+		{
+			name:     "analyze without name",
+			query:    "ANALYZE",
+			parseErr: true,
+		},
+		{
+			name:     "pragma",
+			query:    "PRAGMA table_info(guilds)",
+			parseErr: true,
+		},
+		{
+			name:     "attach",
+			query:    "ATTACH DATABASE 'owobot.db' AS o",
+			parseErr: true,
+		},
+		{
+			name:     "detach",
+			query:    "DETACH o",
+			parseErr: true,
+		},
+		{
+			name:     "vacuum",
+			query:    "VACUUM INTO 'copy.db'",
+			parseErr: true,
+		},
+		{
+			name:     "in subquery",
+			query:    "SELECT * FROM a WHERE x IN (SELECT id FROM guilds)",
+			parseErr: true,
+		},
+		{
+			name:     "scalar subquery",
+			query:    "SELECT (SELECT count(*) FROM guilds)",
+			parseErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := Modify(tc.query, "p_", "_s")
+			switch {
+			case tc.err != nil:
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("expected error %q, got %v (output: %s)", tc.err, err, out)
+				}
+			case tc.parseErr:
+				if err == nil {
+					t.Fatalf("expected an error, got output: %s", out)
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case out != tc.expected:
+				t.Errorf("unexpected output\nexpected: %s\n     got: %s", tc.expected, out)
+			}
+		})
+	}
+}
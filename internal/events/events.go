@@ -0,0 +1,130 @@
+/*
+ * owobot - Your server's guardian and entertainer
+ * Copyright (C) 2023 owobot Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package events implements a bus for owobot's own domain events, such as
+// tickets being opened or members being approved, so that other systems
+// can react to them.
+package events
+
+import (
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Event is an owobot domain event
+type Event interface {
+	// Name returns the name of the event, such as "ticketOpened"
+	Name() string
+}
+
+// Handler is a function that handles published events. Handlers are called
+// synchronously by the publisher, so they shouldn't block.
+type Handler func(s *discordgo.Session, e Event)
+
+var (
+	mu       sync.RWMutex
+	handlers []Handler
+)
+
+// Subscribe adds a handler that will be called for every published event
+func Subscribe(h Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+	handlers = append(handlers, h)
+}
+
+// Publish calls all the subscribed handlers with the given event
+func Publish(s *discordgo.Session, e Event) {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, h := range handlers {
+		h(s, e)
+	}
+}
+
+// TicketOpened is published when a ticket is opened
+type TicketOpened struct {
+	GuildID   string          `json:"guild_id"`
+	ChannelID string          `json:"channel_id"`
+	User      *discordgo.User `json:"user"`
+	Executor  *discordgo.User `json:"executor"`
+}
+
+func (TicketOpened) Name() string { return "ticketOpened" }
+
+// TicketClosed is published when a ticket is closed
+type TicketClosed struct {
+	GuildID   string          `json:"guild_id"`
+	ChannelID string          `json:"channel_id"`
+	User      *discordgo.User `json:"user"`
+	Executor  *discordgo.User `json:"executor"`
+}
+
+func (TicketClosed) Name() string { return "ticketClosed" }
+
+// VettingApproved is published when a member is approved through vetting
+type VettingApproved struct {
+	GuildID  string          `json:"guild_id"`
+	User     *discordgo.User `json:"user"`
+	Role     *discordgo.Role `json:"role"`
+	Approver *discordgo.User `json:"approver"`
+}
+
+func (VettingApproved) Name() string { return "vettingApproved" }
+
+// StarboardAdded is published when a message is added to the starboard
+type StarboardAdded struct {
+	GuildID   string             `json:"guild_id"`
+	ChannelID string             `json:"channel_id"`
+	Message   *discordgo.Message `json:"message"`
+	Stars     int                `json:"stars"`
+}
+
+func (StarboardAdded) Name() string { return "starboardAdded" }
+
+// PollVoted is published when a vote is recorded in a poll. Since polls
+// are anonymous, the voter isn't included.
+type PollVoted struct {
+	GuildID   string `json:"guild_id"`
+	ChannelID string `json:"channel_id"`
+	MessageID string `json:"message_id"`
+	Option    int    `json:"option"`
+}
+
+func (PollVoted) Name() string { return "pollVoted" }
+
+// RateLimitWarning is published when a member is warned that
+// they're close to one of the rate limits.
+type RateLimitWarning struct {
+	GuildID string `json:"guild_id"`
+	UserID  string `json:"user_id"`
+	Limit   string `json:"limit"`
+}
+
+func (RateLimitWarning) Name() string { return "rateLimitWarning" }
+
+// RateLimitExceeded is published when a member is kicked
+// for exceeding one of the rate limits.
+type RateLimitExceeded struct {
+	GuildID string `json:"guild_id"`
+	UserID  string `json:"user_id"`
+	Limit   string `json:"limit"`
+}
+
+func (RateLimitExceeded) Name() string { return "rateLimitExceeded" }
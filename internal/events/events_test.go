@@ -0,0 +1,48 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestPublish(t *testing.T) {
+	var got []Event
+	Subscribe(func(s *discordgo.Session, e Event) {
+		got = append(got, e)
+	})
+
+	Publish(nil, PollVoted{GuildID: "1", Option: 2})
+	Publish(nil, TicketOpened{GuildID: "1", ChannelID: "3"})
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(got))
+	}
+	if e, ok := got[0].(PollVoted); !ok || e.Option != 2 {
+		t.Errorf("expected the PollVoted event to be delivered, got %#v", got[0])
+	}
+	if e, ok := got[1].(TicketOpened); !ok || e.ChannelID != "3" {
+		t.Errorf("expected the TicketOpened event to be delivered, got %#v", got[1])
+	}
+}
+
+func TestEventNames(t *testing.T) {
+	all := []Event{
+		TicketOpened{},
+		TicketClosed{},
+		VettingApproved{},
+		StarboardAdded{},
+		PollVoted{},
+		RateLimitWarning{},
+		RateLimitExceeded{},
+	}
+
+	seen := map[string]bool{}
+	for _, e := range all {
+		name := e.Name()
+		if name == "" || seen[name] {
+			t.Errorf("event %T has an empty or duplicate name %q", e, name)
+		}
+		seen[name] = true
+	}
+}
@@ -0,0 +1,242 @@
+/*
+ * owobot - Your server's guardian and entertainer
+ * Copyright (C) 2023 owobot Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package shards manages the gateway sessions for the shards run by this
+// owobot process, so that systems can add handlers to all of them and find
+// the session responsible for a given guild.
+package shards
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/logger/log"
+)
+
+// identifyInterval is how long Discord requires between
+// identify requests in the same rate limit bucket
+const identifyInterval = 5 * time.Second
+
+var (
+	mu       = sync.RWMutex{}
+	sessions = map[int]*discordgo.Session{}
+	count    = 1
+)
+
+// Open opens a gateway session for each of the given shard IDs out of count
+// shards. If ids is empty, all the shards are run. The base session is used
+// for the first shard, and the sessions for the other shards are copies of it
+// that share its HTTP client and rate limiter. Discord puts each shard in the
+// identify rate limit bucket id % maxConcurrency, so sessions are opened in
+// rounds containing at most one shard from each bucket.
+func Open(base *discordgo.Session, shardCount int, ids []int, maxConcurrency int) error {
+	if shardCount < 1 {
+		return fmt.Errorf("invalid shard count: %d", shardCount)
+	}
+
+	if len(ids) == 0 {
+		for id := 0; id < shardCount; id++ {
+			ids = append(ids, id)
+		}
+	}
+
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	for _, id := range ids {
+		if id < 0 || id >= shardCount {
+			return fmt.Errorf("shard id %d is out of range for %d shards", id, shardCount)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	count = shardCount
+	for i, round := range identifyRounds(ids, maxConcurrency) {
+		if i > 0 {
+			time.Sleep(identifyInterval)
+		}
+
+		for _, id := range round {
+			sess := base
+			if len(sessions) > 0 {
+				sess = newSession(base)
+			}
+			sess.ShardID = id
+			sess.ShardCount = shardCount
+
+			err := sess.Open()
+			if err != nil {
+				closeAll()
+				return fmt.Errorf("shard %d: %w", id, err)
+			}
+			sessions[id] = sess
+
+			if shardCount > 1 {
+				log.Info("Shard connected").Int("shard", id).Int("count", shardCount).Send()
+			}
+		}
+	}
+
+	return nil
+}
+
+// identifyRounds groups the given sorted shard IDs into rounds that can be
+// identified at the same time. Each round contains at most one shard from
+// each rate limit bucket, and the rounds have to be identifyInterval apart.
+func identifyRounds(ids []int, maxConcurrency int) [][]int {
+	maxConcurrency = max(maxConcurrency, 1)
+
+	var rounds [][]int
+	// next contains the index of the next round for each bucket
+	next := map[int]int{}
+	for _, id := range ids {
+		bucket := id % maxConcurrency
+		round := next[bucket]
+		next[bucket]++
+
+		if round == len(rounds) {
+			rounds = append(rounds, nil)
+		}
+		rounds[round] = append(rounds[round], id)
+	}
+	return rounds
+}
+
+// newSession creates a copy of base for another shard
+func newSession(base *discordgo.Session) *discordgo.Session {
+	// discordgo.New never returns an error
+	sess, _ := discordgo.New(base.Identify.Token)
+	sess.Identify.Intents = base.Identify.Intents
+	sess.StateEnabled = base.StateEnabled
+	sess.State.TrackMembers = base.State.TrackMembers
+	sess.State.TrackRoles = base.State.TrackRoles
+	sess.State.TrackChannels = base.State.TrackChannels
+	sess.SyncEvents = base.SyncEvents
+	// REST rate limits apply to the bot as a whole, so every
+	// shard has to share the same client and rate limiter.
+	sess.Client = base.Client
+	sess.Ratelimiter = base.Ratelimiter
+	return sess
+}
+
+// Close closes all the sessions
+func Close() {
+	mu.Lock()
+	defer mu.Unlock()
+	closeAll()
+}
+
+// closeAll is the same as Close, but it expects mu to already be locked.
+func closeAll() {
+	for id, sess := range sessions {
+		err := sess.Close()
+		if err != nil {
+			log.Warn("Error closing shard session").Int("shard", id).Err(err).Send()
+		}
+		delete(sessions, id)
+	}
+}
+
+// Sessions returns the sessions for all the shards run by this process, ordered by shard ID
+func Sessions() []*discordgo.Session {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	out := make([]*discordgo.Session, 0, len(sessions))
+	for _, id := range sortedIDs() {
+		out = append(out, sessions[id])
+	}
+	return out
+}
+
+// sortedIDs returns the IDs of the shards run by this process in order.
+// It expects mu to already be locked.
+func sortedIDs() []int {
+	ids := make([]int, 0, len(sessions))
+	for id := range sessions {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// AddHandler adds an event handler to the sessions for all shards
+func AddHandler(handler any) {
+	for _, sess := range Sessions() {
+		sess.AddHandler(handler)
+	}
+}
+
+// Guilds returns the guilds in the state of every shard run by this process
+func Guilds() []*discordgo.Guild {
+	var out []*discordgo.Guild
+	for _, sess := range Sessions() {
+		sess.State.RLock()
+		out = append(out, sess.State.Guilds...)
+		sess.State.RUnlock()
+	}
+	return out
+}
+
+// shardID returns the ID of the shard that receives events for
+// the given guild. It expects mu to already be locked.
+func shardID(guildID string) int {
+	id, err := strconv.ParseUint(guildID, 10, 64)
+	if err != nil || count <= 1 {
+		return 0
+	}
+	return int((id >> 22) % uint64(count))
+}
+
+// Local checks whether the shard for the given guild is run by this process.
+// Background work for a guild, such as scheduled tasks, should only be done
+// by the process that runs its shard, so that it isn't done more than once.
+// If no sessions have been opened, every guild is considered local.
+func Local(guildID string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	if len(sessions) == 0 {
+		return true
+	}
+	_, ok := sessions[shardID(guildID)]
+	return ok
+}
+
+// ForGuild returns the session for the shard that receives events for the given
+// guild. If that shard isn't run by this process, or the guild ID is empty, the
+// session for the first shard is returned instead, which can still be used for
+// REST requests. If no sessions have been opened, fallback is returned.
+func ForGuild(guildID string, fallback *discordgo.Session) *discordgo.Session {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if sess, ok := sessions[shardID(guildID)]; ok && guildID != "" {
+		return sess
+	}
+
+	if ids := sortedIDs(); len(ids) > 0 {
+		return sessions[ids[0]]
+	}
+	return fallback
+}
@@ -0,0 +1,29 @@
+package shards
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIdentifyRounds(t *testing.T) {
+	cases := []struct {
+		ids            []int
+		maxConcurrency int
+		expected       [][]int
+	}{
+		{[]int{0, 1, 2, 3, 4, 5}, 2, [][]int{{0, 1}, {2, 3}, {4, 5}}},
+		{[]int{0, 1, 2}, 1, [][]int{{0}, {1}, {2}}},
+		{[]int{0, 1, 2}, 0, [][]int{{0}, {1}, {2}}},
+		{[]int{0, 1, 2}, 16, [][]int{{0, 1, 2}}},
+		// Shards 1 and 3 are in the same bucket, so they can't be identified together
+		{[]int{1, 3, 4}, 2, [][]int{{1, 4}, {3}}},
+		{[]int{2, 6, 10, 3}, 4, [][]int{{2, 3}, {6}, {10}}},
+	}
+
+	for _, c := range cases {
+		got := identifyRounds(c.ids, c.maxConcurrency)
+		if !slices.EqualFunc(got, c.expected, slices.Equal[[]int]) {
+			t.Errorf("identifyRounds(%v, %d) = %v, expected %v", c.ids, c.maxConcurrency, got, c.expected)
+		}
+	}
+}
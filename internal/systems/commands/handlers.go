@@ -18,11 +18,14 @@ func onCmd(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	mu.Lock()
 	cmdFn, ok := cmds[data.Name]
 	if !ok {
-		mu.Unlock()
-		return
+		cmdFn, ok = guildCmds[i.GuildID][data.Name]
 	}
 	mu.Unlock()
 
+	if !ok {
+		return
+	}
+
 	err := cmdFn(s, i)
 	if err != nil {
 		log.Warn("Error in command function").Str("cmd", data.Name).Err(err).Send()
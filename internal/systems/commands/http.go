@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/logger/log"
+)
+
+const (
+	// responseTimeout is how long Discord waits for the
+	// response to an interaction delivered over HTTP
+	responseTimeout = 3 * time.Second
+	// maxInteractionSize is the maximum size in bytes of an interaction request body
+	maxInteractionSize = 1 << 20
+	// maxTimestampSkew is how far the timestamp of an interaction request may be
+	// from the current time. Older requests are rejected so that they can't be replayed.
+	maxTimestampSkew = 5 * time.Second
+)
+
+// interactionResponse is an interaction response captured by [responseInterceptor]
+type interactionResponse struct {
+	contentType string
+	body        []byte
+	// written is closed once the response has been sent to Discord
+	written chan struct{}
+}
+
+// responseInterceptor is an HTTP transport for the session's client that captures
+// responses to interactions delivered over HTTP, so that they can be sent as the
+// response to Discord's request instead of through the interaction callback
+// endpoint. This lets the existing handlers respond the same way regardless of
+// how the interaction was delivered.
+type responseInterceptor struct {
+	next    http.RoundTripper
+	mu      sync.Mutex
+	pending map[string]chan *interactionResponse
+}
+
+// expect starts capturing the response to the interaction with the given ID
+func (ri *responseInterceptor) expect(id string) chan *interactionResponse {
+	ch := make(chan *interactionResponse, 1)
+	ri.mu.Lock()
+	ri.pending[id] = ch
+	ri.mu.Unlock()
+	return ch
+}
+
+// forget stops capturing the response to the interaction with the given ID
+func (ri *responseInterceptor) forget(id string) {
+	ri.mu.Lock()
+	delete(ri.pending, id)
+	ri.mu.Unlock()
+}
+
+func (ri *responseInterceptor) RoundTrip(req *http.Request) (*http.Response, error) {
+	id, ok := callbackID(req)
+	if !ok {
+		return ri.next.RoundTrip(req)
+	}
+
+	ri.mu.Lock()
+	ch, ok := ri.pending[id]
+	delete(ri.pending, id)
+	ri.mu.Unlock()
+	if !ok {
+		return ri.next.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &interactionResponse{
+		contentType: req.Header.Get("Content-Type"),
+		body:        body,
+		written:     make(chan struct{}),
+	}
+	ch <- resp
+
+	// Wait for the response to be sent, so that handlers don't try to
+	// edit or follow up on it before Discord knows about it.
+	select {
+	case <-resp.written:
+	case <-time.After(responseTimeout):
+	}
+
+	return &http.Response{
+		Status:     "204 No Content",
+		StatusCode: http.StatusNoContent,
+		Proto:      req.Proto,
+		ProtoMajor: req.ProtoMajor,
+		ProtoMinor: req.ProtoMinor,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
+
+// callbackID returns the interaction ID in the URL of a request
+// to the interaction callback endpoint.
+func callbackID(req *http.Request) (string, bool) {
+	if req.Method != http.MethodPost {
+		return "", false
+	}
+
+	// The path ends in /interactions/<id>/<token>/callback
+	parts := strings.Split(strings.TrimSuffix(req.URL.Path, "/"), "/")
+	if len(parts) < 4 || parts[len(parts)-1] != "callback" || parts[len(parts)-4] != "interactions" {
+		return "", false
+	}
+	return parts[len(parts)-3], true
+}
+
+// HTTPHandler returns an HTTP handler for Discord's interactions endpoint. Requests
+// are verified using the application's public key and dispatched to the same
+// commands and interaction handlers as interactions received over the gateway.
+// Responses to these interactions are sent in the HTTP response.
+func HTTPHandler(s *discordgo.Session, publicKey ed25519.PublicKey) http.Handler {
+	next := s.Client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	ri := &responseInterceptor{
+		next:    next,
+		pending: map[string]chan *interactionResponse{},
+	}
+	s.Client.Transport = ri
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxInteractionSize)
+		if !discordgo.VerifyInteraction(r, publicKey) {
+			http.Error(w, "invalid request signature", http.StatusUnauthorized)
+			return
+		}
+
+		// The timestamp is part of the signed message, so it can be trusted once
+		// the signature has been verified.
+		if !timestampFresh(r.Header.Get("X-Signature-Timestamp"), time.Now()) {
+			http.Error(w, "stale request timestamp", http.StatusUnauthorized)
+			return
+		}
+
+		i := &discordgo.Interaction{}
+		err := json.NewDecoder(r.Body).Decode(i)
+		if err != nil {
+			http.Error(w, "invalid interaction", http.StatusBadRequest)
+			return
+		}
+
+		if i.Type == discordgo.InteractionPing {
+			writeJSON(w, discordgo.InteractionResponse{Type: discordgo.InteractionResponsePong})
+			return
+		}
+
+		ch := ri.expect(i.ID)
+		defer ri.forget(i.ID)
+
+		dispatchInteraction(s, &discordgo.InteractionCreate{Interaction: i})
+
+		select {
+		case resp := <-ch:
+			defer close(resp.written)
+			w.Header().Set("Content-Type", resp.contentType)
+			_, err = io.Copy(w, bytes.NewReader(resp.body))
+			if err != nil {
+				log.Warn("Error writing interaction response").Err(err).Send()
+			} else if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		case <-time.After(responseTimeout):
+			log.Warn("Interaction wasn't responded to in time").Str("id", i.ID).Send()
+			http.Error(w, "no response", http.StatusInternalServerError)
+		}
+	})
+}
+
+// timestampFresh checks whether the given unix timestamp
+// is within [maxTimestampSkew] of now.
+func timestampFresh(timestamp string, now time.Time) bool {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	skew := now.Sub(time.Unix(sec, 0))
+	return skew <= maxTimestampSkew && skew >= -maxTimestampSkew
+}
+
+// writeJSON writes v to w as JSON
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		log.Warn("Error writing interaction response").Err(err).Send()
+	}
+}
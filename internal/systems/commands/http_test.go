@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// failTransport fails every request, so that nothing is sent to Discord
+type failTransport struct{}
+
+func (failTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("unexpected request to discord")
+}
+
+// newTestEndpoint returns an HTTP interactions endpoint and the key that signs requests to it
+func newTestEndpoint(t *testing.T) (*httptest.Server, ed25519.PrivateKey) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := discordgo.New("Bot test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Client.Transport = failTransport{}
+
+	srv := httptest.NewServer(HTTPHandler(s, pub))
+	t.Cleanup(srv.Close)
+	return srv, priv
+}
+
+// postInteraction sends a signed interaction request with the given timestamp to the endpoint
+func postInteraction(t *testing.T, srv *httptest.Server, key ed25519.PrivateKey, timestamp time.Time, body string) *http.Response {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	sig := ed25519.Sign(key, []byte(ts+body))
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Signature-Ed25519", hex.EncodeToString(sig))
+	req.Header.Set("X-Signature-Timestamp", ts)
+
+	res, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { res.Body.Close() })
+	return res
+}
+
+// decodeResponse decodes the interaction response in res
+func decodeResponse(t *testing.T, res *http.Response) discordgo.InteractionResponse {
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %s", res.Status)
+	}
+
+	var resp discordgo.InteractionResponse
+	err := json.NewDecoder(res.Body).Decode(&resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestHTTPPing(t *testing.T) {
+	srv, key := newTestEndpoint(t)
+	res := postInteraction(t, srv, key, time.Now(), `{"id":"1","type":1}`)
+	if resp := decodeResponse(t, res); resp.Type != discordgo.InteractionResponsePong {
+		t.Errorf("expected a pong, got response type %d", resp.Type)
+	}
+}
+
+func TestHTTPBadSignature(t *testing.T) {
+	srv, _ := newTestEndpoint(t)
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := postInteraction(t, srv, otherKey, time.Now(), `{"id":"1","type":1}`)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %s", res.Status)
+	}
+}
+
+func TestHTTPStaleTimestamp(t *testing.T) {
+	srv, key := newTestEndpoint(t)
+	for _, ts := range []time.Time{time.Now().Add(-time.Minute), time.Now().Add(time.Minute)} {
+		res := postInteraction(t, srv, key, ts, `{"id":"1","type":1}`)
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected status 401 for timestamp %s, got %s", ts, res.Status)
+		}
+	}
+}
+
+func TestHTTPDispatch(t *testing.T) {
+	srv, key := newTestEndpoint(t)
+
+	mu.Lock()
+	cmds["httptest"] = func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Content: "hi " + i.Member.User.ID},
+		})
+	}
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		delete(cmds, "httptest")
+		mu.Unlock()
+	})
+
+	body := `{"id":"2","type":2,"token":"tok","guild_id":"3","member":{"user":{"id":"4"}},"data":{"id":"5","name":"httptest","type":1}}`
+	resp := decodeResponse(t, postInteraction(t, srv, key, time.Now(), body))
+	if resp.Type != discordgo.InteractionResponseChannelMessageWithSource {
+		t.Errorf("expected a message response, got response type %d", resp.Type)
+	} else if resp.Data == nil || resp.Data.Content != "hi 4" {
+		t.Errorf("expected the command's response to be sent, got %+v", resp.Data)
+	}
+}
@@ -23,19 +23,27 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	"go.elara.ws/logger/log"
+	"go.elara.ws/owobot/internal/shards"
 	"go.elara.ws/owobot/internal/util"
 )
 
 var (
-	mu   = sync.Mutex{}
-	cmds = map[string]CmdFunc{}
-	acs  = []*discordgo.ApplicationCommand{}
+	mu        = sync.Mutex{}
+	cmds      = map[string]CmdFunc{}
+	guildCmds = map[string]map[string]CmdFunc{}
+	acs       = []*discordgo.ApplicationCommand{}
 )
 
 type CmdFunc func(s *discordgo.Session, i *discordgo.InteractionCreate) error
 
+// GuildCommand represents a command that's only available in a single guild
+type GuildCommand struct {
+	Func    CmdFunc
+	Command *discordgo.ApplicationCommand
+}
+
 func Init(s *discordgo.Session) error {
-	s.AddHandler(onCmd)
+	shards.AddHandler(dispatchInteraction)
 	_, err := s.ApplicationCommandBulkOverwrite(s.State.Application.ID, "", acs)
 	acs = nil // Allow the ACs to be GC'd
 	return err
@@ -59,6 +67,35 @@ func Register(s *discordgo.Session, fn CmdFunc, ac *discordgo.ApplicationCommand
 	acs = append(acs, ac)
 }
 
+// SetGuildCommands replaces all the commands specific to the given guild with gcs
+// and publishes them to discord. Commands whose names conflict with a global command
+// or an earlier command in gcs are skipped.
+func SetGuildCommands(s *discordgo.Session, guildID string, gcs []GuildCommand) error {
+	fns := map[string]CmdFunc{}
+	guildACs := []*discordgo.ApplicationCommand{}
+
+	mu.Lock()
+	for _, gc := range gcs {
+		if _, ok := cmds[gc.Command.Name]; ok {
+			log.Warn("Guild command conflicts with a global command, skipping").Str("cmd", gc.Command.Name).Str("guild-id", guildID).Send()
+			continue
+		}
+
+		if _, ok := fns[gc.Command.Name]; ok {
+			log.Warn("Duplicate guild command, skipping").Str("cmd", gc.Command.Name).Str("guild-id", guildID).Send()
+			continue
+		}
+
+		fns[gc.Command.Name] = gc.Func
+		guildACs = append(guildACs, gc.Command)
+	}
+	guildCmds[guildID] = fns
+	mu.Unlock()
+
+	_, err := s.ApplicationCommandBulkOverwrite(s.State.Application.ID, guildID, guildACs)
+	return err
+}
+
 // commandSync checks if any registered commands have been removed and, if so,
 // deletes them.
 func commandSync(s *discordgo.Session) error {
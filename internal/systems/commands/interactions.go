@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// InteractionHandler handles an interaction, such as a component or modal interaction
+type InteractionHandler func(s *discordgo.Session, i *discordgo.InteractionCreate)
+
+// interactionHandlers contains the handlers added using [AddInteractionHandler]
+var interactionHandlers []InteractionHandler
+
+// AddInteractionHandler adds a handler for interactions that aren't application
+// commands. Unlike handlers added directly to the session, these handlers also
+// receive interactions delivered to the HTTP interactions endpoint.
+func AddInteractionHandler(fn InteractionHandler) {
+	mu.Lock()
+	interactionHandlers = append(interactionHandlers, fn)
+	mu.Unlock()
+}
+
+// dispatchInteraction routes an interaction to the command it's for and to
+// all the interaction handlers. Like the session does for its own handlers,
+// each handler is run in its own goroutine.
+func dispatchInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	mu.Lock()
+	handlers := interactionHandlers
+	mu.Unlock()
+
+	go onCmd(s, i)
+	for _, fn := range handlers {
+		go fn(s, i)
+	}
+}
@@ -22,17 +22,18 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"go.elara.ws/logger/log"
 	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/shards"
 )
 
 func Init(s *discordgo.Session) error {
-	s.AddHandler(onGuildCreate)
-	return guildSync(s)
+	shards.AddHandler(onGuildCreate)
+	return guildSync()
 }
 
-// guildSync looks through all the guilds that the bot is in,
+// guildSync looks through all the guilds that the bot's shards are in,
 // and if any of them don't exist in the database, it adds them.
-func guildSync(s *discordgo.Session) error {
-	for _, guild := range s.State.Guilds {
+func guildSync() error {
+	for _, guild := range shards.Guilds() {
 		err := db.CreateGuild(guild.ID)
 		if err != nil {
 			return err
@@ -5,6 +5,7 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	"go.elara.ws/logger/log"
+	"go.elara.ws/owobot/internal/shards"
 )
 
 var (
@@ -12,10 +13,10 @@ var (
 	inviteMap = map[string]*discordgo.Invite{}
 )
 
-// populateInviteMap gets invites from all the guilds this bot is in
-// and adds them to the invite map.
+// populateInviteMap gets invites from all the guilds the bot's
+// shards are in and adds them to the invite map.
 func populateInviteMap(s *discordgo.Session) {
-	for _, guild := range s.State.Guilds {
+	for _, guild := range shards.Guilds() {
 		invites, err := s.GuildInvites(guild.ID)
 		if err != nil {
 			log.Warn("Error getting invites for guild").Str("guild-id", guild.ID).Str("task", "populate-invites").Send()
@@ -1,12 +1,15 @@
 package members
 
-import "github.com/bwmarrin/discordgo"
+import (
+	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/owobot/internal/shards"
+)
 
 func Init(s *discordgo.Session) error {
 	go populateInviteMap(s)
-	s.AddHandler(onMemberAdd)
-	s.AddHandler(onMemberUpdate)
-	s.AddHandler(onMemberLeave)
-	s.AddHandler(onChannelDelete)
+	shards.AddHandler(onMemberAdd)
+	shards.AddHandler(onMemberUpdate)
+	shards.AddHandler(onMemberLeave)
+	shards.AddHandler(onChannelDelete)
 	return nil
 }
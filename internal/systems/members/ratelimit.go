@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/owobot/internal/events"
 	"go.elara.ws/owobot/internal/limiter"
 )
 
@@ -45,6 +46,8 @@ func handleRatelimit(s *discordgo.Session, limit, guildID, userID string) error
 		if err != nil {
 			return err
 		}
+
+		events.Publish(s, events.RateLimitWarning{GuildID: guildID, UserID: userID, Limit: limit})
 	} else if l.IsDepleted(key) {
 		// We ignore the error here because even if the message can't be sent,
 		// we want to kick the user from the server anyway.
@@ -54,6 +57,8 @@ func handleRatelimit(s *discordgo.Session, limit, guildID, userID string) error
 		if err != nil {
 			return err
 		}
+
+		events.Publish(s, events.RateLimitExceeded{GuildID: guildID, UserID: userID, Limit: limit})
 	}
 
 	return nil
@@ -1,6 +1,14 @@
 package plugins
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/bwmarrin/discordgo"
 	"github.com/dop251/goja"
 	"github.com/dop251/goja_nodejs/eventloop"
@@ -9,15 +17,51 @@ import (
 	"go.elara.ws/owobot/internal/util"
 )
 
-// Plugins is a list of plugins
-var Plugins []Plugin
+var (
+	pluginsMtx = sync.RWMutex{}
+	// Plugins is a list of plugins
+	Plugins []*Plugin
+)
 
 // Plugin represents an owobot plugin
 type Plugin struct {
-	Info     db.PluginInfo
-	Commands []Command
-	Loop     *eventloop.EventLoop
-	api      *owobotAPI
+	Info          db.PluginInfo
+	Commands      []Command
+	SlashCommands []SlashCommand
+	Loop          *eventloop.EventLoop
+	path          string
+	api           *owobotAPI
+	// prev is the information about the previously loaded
+	// version of the plugin, which is passed to its init function.
+	prev db.PluginInfo
+	// release releases the resources held by the plugin's builtin APIs
+	release func()
+}
+
+// addPlugin adds a plugin to the plugin list
+func addPlugin(plugin *Plugin) {
+	pluginsMtx.Lock()
+	defer pluginsMtx.Unlock()
+	Plugins = append(Plugins, plugin)
+	plugin.api.active.Store(true)
+}
+
+// removePlugin removes a plugin from the plugin list
+func removePlugin(plugin *Plugin) {
+	pluginsMtx.Lock()
+	defer pluginsMtx.Unlock()
+	Plugins = slices.DeleteFunc(Plugins, func(p *Plugin) bool {
+		return p == plugin
+	})
+	plugin.api.active.Store(false)
+}
+
+// allPlugins returns a snapshot of the plugin list that's
+// safe to iterate over while plugins are being reloaded.
+func allPlugins() []*Plugin {
+	pluginsMtx.RLock()
+	defer pluginsMtx.RUnlock()
+	return slices.Clone(Plugins)
 }
 
 // Command represents a plugin command
@@ -28,6 +72,9 @@ type Command struct {
 	OnExec      goja.Value
 	Permissions []int64
 	Subcommands []Command
+	// OnAutocomplete is called with the command's arguments and the partial
+	// argument being typed, and returns autocomplete choices for it.
+	OnAutocomplete goja.Value
 }
 
 func (c Command) usage() string {
@@ -39,14 +86,38 @@ func (c Command) usage() string {
 }
 
 type owobotAPI struct {
-	PluginInfo db.PluginInfo
-	Init       goja.Value
-	OnEnable   goja.Value
-	OnDisable  goja.Value
-	Commands   []Command
+	PluginInfo     db.PluginInfo
+	Init           goja.Value
+	OnEnable       goja.Value
+	OnDisable      goja.Value
+	OnUnload       goja.Value
+	OnConfigChange goja.Value
+	Commands       []Command
+	SlashCommands  []SlashCommand
+	Migrations     []Migration
 
 	path string
 	loop *eventloop.EventLoop
+	sess *discordgo.Session
+	// stopWatchdog is closed to stop the watchdog of the plugin's event loop
+	stopWatchdog chan struct{}
+
+	mu                  sync.Mutex
+	interactionHandlers []interactionHandler
+	schedules           []schedule
+	timerHandlers       map[string]goja.Callable
+	sessions            map[*discordgo.Session]*discordgo.Session
+	violations          []time.Time
+	// guildID is the ID of the guild whose event or command is currently
+	// being handled. It's only accessed on the plugin's event loop.
+	guildID string
+	// onError, if set, is called on the event loop with the errors
+	// returned by calls to the plugin made through [invoke].
+	onError func(source string, err error)
+	// active is set while the plugin is in the plugin list. Event handlers
+	// of inactive plugins aren't called, so that a new version of a plugin
+	// doesn't receive events while the old one is still loaded.
+	active atomic.Bool
 }
 
 func (oa *owobotAPI) Enabled(guildID string) bool {
@@ -61,7 +132,9 @@ func (oa *owobotAPI) RespondEphemeral(s *discordgo.Session, i *discordgo.Interac
 	return util.RespondEphemeral(s, i, content)
 }
 
-// On adds an event handler function for the given event type
+// On adds an event handler function for the given event type. Discord events use
+// the names of their discordgo types, such as "MessageCreate", while owobot's
+// own events use lower camel case names, such as "ticketOpened".
 func (oa *owobotAPI) On(eventType string, fn goja.Value) {
 	if !oa.PluginInfo.IsValid() {
 		log.Warn("No plugin information provided, ignoring handler registration.").Str("path", oa.path).Send()
@@ -80,13 +153,22 @@ func (oa *owobotAPI) On(eventType string, fn goja.Value) {
 	handlersMtx.Lock()
 	defer handlersMtx.Unlock()
 
-	oa.loop.RunOnLoop(func(vm *goja.Runtime) {
-		this := vm.ToValue(oa)
+	handlerMap[eventType] = append(handlerMap[eventType], Handler{
+		PluginName: oa.PluginInfo.Name,
+		owner:      oa,
+		Func: func(s *discordgo.Session, data any) {
+			// Events are dispatched asynchronously so that a slow handler
+			// can't hold up delivery to other plugins or core systems.
+			invoke(oa, eventGuildID(data), "event "+eventType, func(vm *goja.Runtime) error {
+				if ce, ok := data.(customEvent); ok {
+					payload, err := ce.payload()
+					if err != nil {
+						return err
+					}
+					data = payload
+				}
 
-		handlerMap[eventType] = append(handlerMap[eventType], Handler{
-			PluginName: oa.PluginInfo.Name,
-			Func: func(s *discordgo.Session, data any) {
-				_, err := callable(this, vm.ToValue(s), vm.ToValue(data))
+				_, err := callable(vm.ToValue(oa), vm.ToValue(oa.session(s)), vm.ToValue(data))
 				if err != nil {
 					log.Error("Exception thrown in plugin function").
 						Str("plugin", oa.PluginInfo.Name).
@@ -94,7 +176,47 @@ func (oa *owobotAPI) On(eventType string, fn goja.Value) {
 						Err(err).
 						Send()
 				}
-			},
-		})
+				return err
+			})
+		},
 	})
 }
+
+// Emit sends a custom event to all the plugins that handle it. The event's name
+// is prefixed with the name of the emitting plugin, so an event called "levelUp"
+// emitted by a plugin called "xp" is handled using owobot.on("xp:levelUp", ...).
+// The payload must contain a guildID, and the event is only delivered to plugins
+// enabled in that guild. Each receiver gets its own deep copy of the payload.
+func (oa *owobotAPI) Emit(name string, payload goja.Value) error {
+	guildID, data, err := oa.guildPayload(payload)
+	if err != nil {
+		return fmt.Errorf("event %w", err)
+	}
+
+	dispatchEvent(oa.sess, oa.PluginInfo.Name+":"+name, customEvent{GuildID: guildID, Payload: data})
+	return nil
+}
+
+// guildPayload encodes a payload as JSON and returns the guildID it contains.
+// The plugin must be enabled in that guild.
+func (oa *owobotAPI) guildPayload(payload goja.Value) (string, []byte, error) {
+	var exported any
+	if payload != nil {
+		exported = payload.Export()
+	}
+
+	data, err := json.Marshal(exported)
+	if err != nil {
+		return "", nil, fmt.Errorf("payload must be JSON-serializable: %w", err)
+	}
+
+	obj, _ := exported.(map[string]any)
+	guildID, _ := obj["guildID"].(string)
+	if guildID == "" {
+		return "", nil, errors.New("payload must contain a guildID")
+	} else if !pluginEnabled(guildID, oa.PluginInfo.Name) {
+		return "", nil, fmt.Errorf("plugin %q is not enabled in guild %s", oa.PluginInfo.Name, guildID)
+	}
+
+	return guildID, data, nil
+}
@@ -0,0 +1,155 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dop251/goja"
+	"github.com/kballard/go-shellquote"
+	"go.elara.ws/logger/log"
+)
+
+const (
+	// autocompleteTimeout is how long a plugin has to return autocomplete choices.
+	// Discord discards autocomplete responses sent more than three seconds after
+	// the interaction, so this leaves some room for the response itself.
+	autocompleteTimeout = 2 * time.Second
+	// maxChoices is the maximum amount of autocomplete choices Discord accepts
+	maxChoices = 25
+	// maxChoiceLen is the maximum length of an autocomplete choice's name and value
+	maxChoiceLen = 100
+)
+
+// errAutocompleteTimeout is used to interrupt autocomplete callbacks that run past their deadline
+var errAutocompleteTimeout = errors.New("autocomplete callback exceeded its deadline")
+
+// getArgChoices gets autocomplete choices for the arguments of the plugin command
+// in cmdStr by calling the command's onAutocomplete callback.
+func getArgChoices(guildID, cmdStr string, member *discordgo.Member) []*discordgo.ApplicationCommandOptionChoice {
+	deadline := time.Now().Add(autocompleteTimeout)
+
+	// Everything before the argument currently being typed is kept as-is,
+	// so that the choices can be used as the full command string.
+	prefix := strings.TrimRightFunc(cmdStr, func(r rune) bool { return !unicode.IsSpace(r) })
+	partial := cmdStr[len(prefix):]
+
+	args, err := shellquote.Split(prefix)
+	if err != nil {
+		// The user is in the middle of typing a quoted argument
+		return nil
+	}
+
+	for _, plugin := range allPlugins() {
+		if !pluginEnabled(guildID, plugin.Info.Name) {
+			continue
+		}
+
+		cmd, cmdArgs, ok := findCmd(plugin.Commands, args)
+		if !ok || cmd.OnAutocomplete == nil {
+			continue
+		}
+
+		for _, perm := range cmd.Permissions {
+			if member.Permissions&perm == 0 {
+				return nil
+			}
+		}
+
+		values, err := autocomplete(plugin, guildID, cmd, cmdArgs, partial, deadline)
+		if err != nil {
+			log.Warn("Error getting plugin autocomplete choices").
+				Str("plugin", plugin.Info.Name).
+				Str("cmd", cmd.Name).
+				Err(err).
+				Send()
+			return nil
+		}
+
+		return argChoices(prefix, values)
+	}
+
+	return nil
+}
+
+// autocomplete calls a command's onAutocomplete callback on the plugin's event loop.
+// If the callback doesn't return before the deadline, it's interrupted and an error
+// is returned without waiting for the loop.
+func autocomplete(plugin *Plugin, guildID string, cmd Command, args []string, partial string, deadline time.Time) (any, error) {
+	callable, ok := goja.AssertFunction(cmd.OnAutocomplete)
+	if !ok {
+		return nil, errors.New("value in onAutocomplete is not callable")
+	}
+
+	var out any
+	errCh := invoke(plugin.api, guildID, "autocomplete "+cmd.Name, func(vm *goja.Runtime) error {
+		// The call may have waited for other work on the loop,
+		// so it only gets whatever time is left.
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return errAutocompleteTimeout
+		}
+
+		return withDeadline(vm, remaining, errAutocompleteTimeout, func() error {
+			val, err := callable(vm.ToValue(cmd), vm.ToValue(args), vm.ToValue(partial))
+			if err != nil {
+				return err
+			}
+			out = val.Export()
+			return nil
+		})
+	})
+
+	select {
+	case err := <-errCh:
+		return out, err
+	case <-time.After(time.Until(deadline)):
+		return nil, errAutocompleteTimeout
+	}
+}
+
+// argChoices converts the values returned by an onAutocomplete callback into Discord
+// autocomplete choices. Each value may be a string or an object with name and value
+// properties. Since the choice replaces the whole command string, the value of each
+// choice is prefixed with the command and the arguments before the one being completed.
+func argChoices(prefix string, values any) (out []*discordgo.ApplicationCommandOptionChoice) {
+	list, ok := values.([]any)
+	if !ok {
+		return nil
+	}
+
+	for _, v := range list {
+		var name, value string
+		switch v := v.(type) {
+		case string:
+			name, value = v, v
+		case map[string]any:
+			value = fmt.Sprint(v["value"])
+			name = value
+			if n, ok := v["name"]; ok {
+				name = fmt.Sprint(n)
+			}
+		default:
+			continue
+		}
+
+		value = prefix + shellquote.Join(value)
+		if name == "" || len(value) > maxChoiceLen {
+			continue
+		}
+
+		out = append(out, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncate(name, maxChoiceLen),
+			Value: value,
+		})
+
+		if len(out) == maxChoices {
+			break
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,139 @@
+package builtins
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// cookieEntry identifies a cookie stored in a [boundedJar]
+type cookieEntry struct {
+	url    *url.URL
+	name   string
+	domain string
+	path   string
+}
+
+// boundedJar is a cookie jar that stores at most max cookies.
+// When it's full, the oldest cookies are evicted first.
+type boundedJar struct {
+	mu      sync.Mutex
+	max     int
+	jar     *cookiejar.Jar
+	entries []cookieEntry
+}
+
+func newBoundedJar(max int) *boundedJar {
+	return &boundedJar{max: max, jar: newCookieJar()}
+}
+
+func newCookieJar() *cookiejar.Jar {
+	// cookiejar.New always returns a nil error
+	jar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	return jar
+}
+
+func (bj *boundedJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	bj.mu.Lock()
+	defer bj.mu.Unlock()
+
+	bj.jar.SetCookies(u, cookies)
+
+	now := time.Now()
+	for _, c := range cookies {
+		entry := cookieEntry{url: u, name: c.Name, domain: c.Domain, path: c.Path}
+		if entry.path == "" || entry.path[0] != '/' {
+			entry.path = defaultCookiePath(u.Path)
+		}
+
+		deleted := c.MaxAge < 0 || (!c.Expires.IsZero() && c.Expires.Before(now))
+		if deleted {
+			bj.remove(entry)
+		} else if bj.stored(entry, c) {
+			bj.remove(entry)
+			bj.entries = append(bj.entries, entry)
+		}
+		// Otherwise, the jar rejected the cookie, such as because its domain
+		// doesn't match the URL, so it doesn't take up any space.
+	}
+
+	for bj.max > 0 && len(bj.entries) > bj.max {
+		oldest := bj.entries[0]
+		bj.entries = bj.entries[1:]
+		bj.jar.SetCookies(oldest.url, []*http.Cookie{{
+			Name:   oldest.name,
+			Domain: oldest.domain,
+			Path:   oldest.path,
+			MaxAge: -1,
+		}})
+	}
+}
+
+func (bj *boundedJar) Cookies(u *url.URL) []*http.Cookie {
+	bj.mu.Lock()
+	defer bj.mu.Unlock()
+	return bj.jar.Cookies(u)
+}
+
+// Clear removes all the cookies stored in the jar
+func (bj *boundedJar) Clear() {
+	bj.mu.Lock()
+	defer bj.mu.Unlock()
+	bj.jar = newCookieJar()
+	bj.entries = nil
+}
+
+// stored checks whether the underlying jar accepted cookie c, identified by entry
+func (bj *boundedJar) stored(entry cookieEntry, c *http.Cookie) bool {
+	scheme := entry.url.Scheme
+	if c.Secure {
+		scheme = "https"
+	}
+
+	host := cookieDomain(entry)
+	if strings.Contains(host, ":") {
+		// IPv6 addresses have to be bracketed in URLs
+		host = "[" + host + "]"
+	}
+
+	u := &url.URL{Scheme: scheme, Host: host, Path: entry.path}
+	for _, sc := range bj.jar.Cookies(u) {
+		if sc.Name == c.Name && sc.Value == c.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// remove removes the entry for the same cookie as entry, if there is one
+func (bj *boundedJar) remove(entry cookieEntry) {
+	for i, e := range bj.entries {
+		if e.name == entry.name && e.path == entry.path && cookieDomain(e) == cookieDomain(entry) {
+			bj.entries = append(bj.entries[:i], bj.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// cookieDomain returns the domain a cookie entry applies to
+func cookieDomain(e cookieEntry) string {
+	if e.domain != "" {
+		return strings.TrimPrefix(strings.ToLower(e.domain), ".")
+	}
+	return strings.ToLower(e.url.Hostname())
+}
+
+// defaultCookiePath returns the default path for cookies set by
+// a response for the given request path, as described in RFC 6265.
+func defaultCookiePath(path string) string {
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return path[:i]
+}
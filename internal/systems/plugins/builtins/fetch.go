@@ -1,23 +1,51 @@
 package builtins
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
-	"net/http/cookiejar"
+	"net/textproto"
+	"os"
 	"runtime/debug"
 	"strings"
+	"time"
 
-	"golang.org/x/net/publicsuffix"
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/eventloop"
+	"go.elara.ws/owobot/internal/db"
 )
 
 // Options contains options for the JavaScript fetch function
 type Options struct {
-	Method        string
-	Body          string
+	Method string
+	// Body is the request body. It may be a string, an ArrayBuffer, or a Uint8Array.
+	Body any
+	// Multipart contains the parts of a multipart/form-data request body.
+	// It can't be used together with Body.
+	Multipart     []FormPart
 	Headers       map[string]any
 	HandleCookies *bool
+	// Timeout is the maximum amount of milliseconds the request may take.
+	// It can't extend the timeout configured for all plugins.
+	Timeout int64
+	// Signal aborts the request when its controller is aborted
+	Signal *AbortSignal
+}
+
+// FormPart represents a single part of a multipart form body
+type FormPart struct {
+	Name string
+	// Value is the part's content. It may be a string, an ArrayBuffer, or a Uint8Array.
+	Value any
+	// Filename makes the part a file upload if it's set
+	Filename    string
+	ContentType string
 }
 
 // Response contains the response object for the JavaScript fetch function
@@ -26,6 +54,7 @@ type Response struct {
 	StatusCode int
 	Headers    http.Header
 	body       []byte
+	vm         *goja.Runtime
 }
 
 func (r Response) JSON() (v any, err error) {
@@ -37,63 +66,317 @@ func (r Response) String() string {
 	return string(r.body)
 }
 
+// ArrayBuffer returns a copy of the response body as an ArrayBuffer
+func (r Response) ArrayBuffer() goja.ArrayBuffer {
+	return r.vm.NewArrayBuffer(bytes.Clone(r.body))
+}
+
+// Base64 returns the response body encoded as standard base64
+func (r Response) Base64() string {
+	return base64.StdEncoding.EncodeToString(r.body)
+}
+
+// AbortSignal is used to abort fetch requests
+type AbortSignal struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Aborted returns whether the signal's controller has been aborted
+func (as *AbortSignal) Aborted() bool {
+	return as.ctx.Err() != nil
+}
+
+// AbortController controls an [AbortSignal]
+type AbortController struct {
+	Signal *AbortSignal
+}
+
+// Abort aborts all the requests using the controller's signal
+func (ac *AbortController) Abort() {
+	ac.Signal.cancel()
+}
+
 // FetchFunc is the fetch function signature
 type FetchFunc = func(string, *Options) (*Response, error)
 
-func fetch(pluginName, pluginVersion string) FetchFunc {
-	// cookiejar.New always returns a nil error
-	jar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+// fetcher makes HTTP requests on behalf of a plugin
+type fetcher struct {
+	vm     *goja.Runtime
+	loop   *eventloop.EventLoop
+	info   db.PluginInfo
+	limits Limits
+	jar    *boundedJar
+}
 
-	return func(url string, opts *Options) (*Response, error) {
-		if opts == nil {
-			t := true
-			opts = &Options{HandleCookies: &t}
-		}
+// registerFetch registers the fetch function and the APIs that go with it
+func registerFetch(vm *goja.Runtime, loop *eventloop.EventLoop, info db.PluginInfo, limits Limits) error {
+	f := &fetcher{
+		vm:     vm,
+		loop:   loop,
+		info:   info,
+		limits: limits,
+		jar:    newBoundedJar(limits.FetchMaxCookies),
+	}
 
-		if opts.HandleCookies == nil {
-			t := true
-			opts.HandleCookies = &t
-		}
+	fetchObj := vm.ToValue(FetchFunc(f.fetch)).ToObject(vm)
+	return errors.Join(
+		fetchObj.Set("async", f.fetchAsync),
+		fetchObj.Set("clearCookies", f.jar.Clear),
+		vm.Set("fetch", fetchObj),
+		vm.Set("AbortController", func(goja.ConstructorCall) *goja.Object {
+			ctx, cancel := context.WithCancel(context.Background())
+			return vm.ToValue(&AbortController{Signal: &AbortSignal{ctx: ctx, cancel: cancel}}).ToObject(vm)
+		}),
+	)
+}
 
-		if opts.Method == "" {
-			opts.Method = http.MethodGet
-		}
+// fetch makes a request and waits for the response
+func (f *fetcher) fetch(url string, opts *Options) (*Response, error) {
+	req, cancel, err := f.newRequest(url, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	return f.do(req, opts)
+}
 
-		req, err := http.NewRequest(opts.Method, url, strings.NewReader(opts.Body))
-		if err != nil {
-			return nil, err
-		}
+// fetchAsync makes a request in the background and returns a promise
+// that resolves with the response.
+func (f *fetcher) fetchAsync(url string, opts *Options) *goja.Promise {
+	promise, resolve, reject := f.vm.NewPromise()
 
-		for key, value := range opts.Headers {
-			req.Header.Add(key, value.(string))
-		}
+	req, cancel, err := f.newRequest(url, opts)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	go func() {
+		defer cancel()
+		resp, err := f.do(req, opts)
+		f.loop.RunOnLoop(func(*goja.Runtime) {
+			if err != nil {
+				reject(err)
+			} else {
+				resolve(resp)
+			}
+		})
+	}()
+
+	return promise
+}
+
+// allowsHost checks whether the plugin may make requests to the given host
+func (f *fetcher) allowsHost(host string) error {
+	if !f.info.Capabilities.AllowsHost(host) {
+		return fmt.Errorf("plugin did not declare access to host %q", host)
+	}
+	if f.limits.FetchHosts != nil && !db.MatchHost(f.limits.FetchHosts, host) {
+		return fmt.Errorf("access to host %q is not allowed by the owobot configuration", host)
+	}
+	return nil
+}
+
+// newRequest creates a request from the given fetch options. This has to be done
+// on the event loop, since the options may contain JavaScript buffers.
+func (f *fetcher) newRequest(url string, opts *Options) (*http.Request, context.CancelFunc, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
 
-		if req.Header.Get("User-Agent") == "" {
-			req.Header.Set("User-Agent", getUserAgent(pluginName, pluginVersion))
+	if opts.HandleCookies == nil {
+		t := true
+		opts.HandleCookies = &t
+	}
+
+	if opts.Method == "" {
+		opts.Method = http.MethodGet
+	}
+
+	body, contentType, err := requestBody(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if opts.Signal != nil {
+		if opts.Signal.Aborted() {
+			return nil, nil, errors.New("request aborted")
 		}
+		ctx = opts.Signal.ctx
+	}
+	if opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(opts.Timeout)*time.Millisecond)
+	}
 
-		client := &http.Client{}
-		if *opts.HandleCookies {
-			client.Jar = jar
+	req, err := http.NewRequestWithContext(ctx, opts.Method, url, bytes.NewReader(body))
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	if err := f.allowsHost(req.URL.Hostname()); err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	for key, value := range opts.Headers {
+		req.Header.Add(key, value.(string))
+	}
+
+	if contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", getUserAgent(f.info.Name, f.info.Version))
+	}
+
+	return req, cancel, nil
+}
+
+// do sends a request and reads its response. It's safe to call outside the event loop.
+func (f *fetcher) do(req *http.Request, opts *Options) (*Response, error) {
+	client := &http.Client{
+		Timeout: f.limits.FetchTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if err := f.allowsHost(req.URL.Hostname()); err != nil {
+				return err
+			}
+			// Keep the default limit of 10 redirects
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return nil
+		},
+	}
+	if opts == nil || opts.HandleCookies == nil || *opts.HandleCookies {
+		client.Jar = f.jar
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, f.requestError(req, err)
+	}
+	defer resp.Body.Close()
+
+	maxSize := f.limits.FetchMaxBodySize
+	if maxSize > 0 && resp.ContentLength > maxSize {
+		return nil, f.limits.exceeded("response body is larger than %d bytes", maxSize)
+	}
+
+	body := io.Reader(resp.Body)
+	if maxSize > 0 {
+		// Read one byte past the limit so we can tell if the body was too large
+		body = io.LimitReader(resp.Body, maxSize+1)
+	}
+
+	responseBody, err := io.ReadAll(body)
+	if err != nil {
+		return nil, f.requestError(req, err)
+	}
+
+	if maxSize > 0 && int64(len(responseBody)) > maxSize {
+		return nil, f.limits.exceeded("response body is larger than %d bytes", maxSize)
+	}
+
+	return &Response{
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		body:       responseBody,
+		vm:         f.vm,
+	}, nil
+}
+
+// requestError converts an error that occurred while making a request.
+// Aborted requests and requests that exceeded their own timeout return
+// regular errors, while requests that exceeded the configured timeout
+// count as limit violations.
+func (f *fetcher) requestError(req *http.Request, err error) error {
+	switch ctxErr := req.Context().Err(); {
+	case errors.Is(ctxErr, context.Canceled):
+		return errors.New("request aborted")
+	case errors.Is(ctxErr, context.DeadlineExceeded):
+		return errors.New("request timed out")
+	case os.IsTimeout(err):
+		return f.limits.exceeded("request took longer than %s", f.limits.FetchTimeout)
+	default:
+		return err
+	}
+}
+
+// quoteEscaper escapes quoted strings in multipart headers
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// requestBody returns the request body described by opts and its content type
+func requestBody(opts *Options) ([]byte, string, error) {
+	if len(opts.Multipart) == 0 {
+		body, err := toBytes(opts.Body)
+		return body, "", err
+	} else if opts.Body != nil {
+		return nil, "", errors.New("body and multipart can't be used together")
+	}
+
+	buf := &bytes.Buffer{}
+	mw := multipart.NewWriter(buf)
+	for _, part := range opts.Multipart {
+		value, err := toBytes(part.Value)
+		if err != nil {
+			return nil, "", fmt.Errorf("multipart field %q: %w", part.Name, err)
 		}
 
-		resp, err := client.Do(req)
+		var w io.Writer
+		if part.Filename != "" {
+			contentType := part.ContentType
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+
+			header := textproto.MIMEHeader{}
+			header.Set("Content-Disposition", fmt.Sprintf(
+				`form-data; name="%s"; filename="%s"`,
+				quoteEscaper.Replace(part.Name),
+				quoteEscaper.Replace(part.Filename),
+			))
+			header.Set("Content-Type", contentType)
+			w, err = mw.CreatePart(header)
+		} else {
+			w, err = mw.CreateFormField(part.Name)
+		}
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
-		defer resp.Body.Close()
 
-		responseBody, err := io.ReadAll(resp.Body)
+		_, err = w.Write(value)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
+	}
+
+	err := mw.Close()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), mw.FormDataContentType(), nil
+}
 
-		return &Response{
-			Status:     resp.Status,
-			StatusCode: resp.StatusCode,
-			Headers:    resp.Header,
-			body:       responseBody,
-		}, nil
+// toBytes converts a JavaScript body value into a byte slice. Buffers are
+// copied so that the plugin can't modify them while a request is in progress.
+func toBytes(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(v), nil
+	case goja.ArrayBuffer:
+		return bytes.Clone(v.Bytes()), nil
+	case []byte:
+		return bytes.Clone(v), nil
+	default:
+		return nil, fmt.Errorf("unsupported body type: %T", v)
 	}
 }
 
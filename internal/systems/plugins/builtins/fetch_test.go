@@ -0,0 +1,238 @@
+package builtins
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dop251/goja"
+	"go.elara.ws/owobot/internal/db"
+)
+
+// newTestFetcher returns a fetcher that's allowed to make requests to srv
+func newTestFetcher(t *testing.T, srv *httptest.Server, limits Limits) *fetcher {
+	t.Helper()
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &fetcher{
+		vm: goja.New(),
+		info: db.PluginInfo{
+			Name:         "test",
+			Version:      "1",
+			Capabilities: db.PluginCapabilities{Hosts: []string{u.Hostname()}},
+		},
+		limits: limits,
+		jar:    newBoundedJar(limits.FetchMaxCookies),
+	}
+}
+
+func mustParseURL(t *testing.T, s string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func cookieNames(cookies []*http.Cookie) string {
+	names := make([]string, len(cookies))
+	for i, c := range cookies {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func TestBoundedJarEviction(t *testing.T) {
+	bj := newBoundedJar(2)
+	u := mustParseURL(t, "https://example.com/")
+
+	for _, name := range []string{"a", "b", "c"} {
+		bj.SetCookies(u, []*http.Cookie{{Name: name, Value: "1"}})
+	}
+
+	if names := cookieNames(bj.Cookies(u)); names != "b,c" {
+		t.Errorf("expected the oldest cookie to be evicted, got %q", names)
+	}
+
+	// Replacing a cookie doesn't take up more space
+	bj.SetCookies(u, []*http.Cookie{{Name: "b", Value: "2"}})
+	if names := cookieNames(bj.Cookies(u)); names != "b,c" {
+		t.Errorf("expected replacing a cookie to keep both cookies, got %q", names)
+	}
+
+	// Deleting a cookie frees up space
+	bj.SetCookies(u, []*http.Cookie{{Name: "b", MaxAge: -1}})
+	bj.SetCookies(u, []*http.Cookie{{Name: "d", Value: "1"}})
+	if names := cookieNames(bj.Cookies(u)); names != "c,d" {
+		t.Errorf("expected the deleted cookie's space to be reused, got %q", names)
+	}
+}
+
+func TestBoundedJarIgnoresRejectedCookies(t *testing.T) {
+	bj := newBoundedJar(2)
+	u := mustParseURL(t, "https://example.com/")
+
+	bj.SetCookies(u, []*http.Cookie{{Name: "a", Value: "1"}, {Name: "b", Value: "1"}})
+	// The jar refuses cookies for domains other than the one that set them
+	for i := 0; i < 3; i++ {
+		bj.SetCookies(u, []*http.Cookie{{Name: "evil", Value: "1", Domain: "evil.com"}})
+	}
+
+	if len(bj.entries) != 2 {
+		t.Errorf("expected rejected cookies not to be recorded, got %d entries", len(bj.entries))
+	}
+	if names := cookieNames(bj.Cookies(u)); names != "a,b" {
+		t.Errorf("expected rejected cookies not to evict stored ones, got %q", names)
+	}
+}
+
+func TestFetchCookies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/set" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			return
+		}
+		c, err := r.Cookie("session")
+		if err == nil {
+			io.WriteString(w, c.Value)
+		}
+	}))
+	defer srv.Close()
+
+	f := newTestFetcher(t, srv, Limits{})
+
+	if _, err := f.fetch(srv.URL+"/set", nil); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := f.fetch(srv.URL+"/get", nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if resp.String() != "abc" {
+		t.Errorf("expected the cookie to be sent back, got %q", resp.String())
+	}
+
+	handleCookies := false
+	resp, err = f.fetch(srv.URL+"/get", &Options{HandleCookies: &handleCookies})
+	if err != nil {
+		t.Fatal(err)
+	} else if resp.String() != "" {
+		t.Errorf("expected no cookies to be sent when cookie handling is disabled, got %q", resp.String())
+	}
+
+	f.jar.Clear()
+	resp, err = f.fetch(srv.URL+"/get", nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if resp.String() != "" {
+		t.Errorf("expected no cookies to be sent after clearing them, got %q", resp.String())
+	}
+}
+
+func TestFetchMultipart(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseMultipartForm(1 << 20)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+
+		io.WriteString(w, r.FormValue("name")+";"+header.Filename+";"+header.Header.Get("Content-Type")+";"+string(data))
+	}))
+	defer srv.Close()
+
+	f := newTestFetcher(t, srv, Limits{})
+	resp, err := f.fetch(srv.URL, &Options{
+		Method: http.MethodPost,
+		Multipart: []FormPart{
+			{Name: "name", Value: "owo"},
+			{Name: "file", Value: []byte("data"), Filename: `a"b.txt`, ContentType: "text/plain"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if resp.StatusCode != http.StatusOK {
+		t.Fatalf("server couldn't parse the multipart body: %s", resp.String())
+	}
+
+	if expected := `owo;a"b.txt;text/plain;data`; resp.String() != expected {
+		t.Errorf("expected %q, got %q", expected, resp.String())
+	}
+
+	_, err = f.fetch(srv.URL, &Options{Body: "x", Multipart: []FormPart{{Name: "a", Value: "b"}}})
+	if err == nil {
+		t.Error("request with both a body and a multipart body succeeded")
+	}
+}
+
+func TestFetchAbort(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	f := newTestFetcher(t, srv, Limits{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ac := &AbortController{Signal: &AbortSignal{ctx: ctx, cancel: cancel}}
+
+	time.AfterFunc(20*time.Millisecond, ac.Abort)
+	_, err := f.fetch(srv.URL, &Options{Signal: ac.Signal})
+	if err == nil || err.Error() != "request aborted" {
+		t.Errorf("expected the request to be aborted, got %v", err)
+	}
+
+	// Requests using a signal that was already aborted aren't sent at all
+	_, err = f.fetch(srv.URL, &Options{Signal: ac.Signal})
+	if err == nil || err.Error() != "request aborted" {
+		t.Errorf("expected the request to be aborted, got %v", err)
+	}
+}
+
+func TestFetchHostAllowlist(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://example.com/", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	f := newTestFetcher(t, srv, Limits{})
+
+	if _, err := f.fetch(srv.URL, nil); err != nil {
+		t.Errorf("request to a declared host failed: %v", err)
+	}
+	if _, err := f.fetch("http://example.com/", nil); err == nil {
+		t.Error("request to an undeclared host succeeded")
+	}
+	if _, err := f.fetch(srv.URL+"/redirect", nil); err == nil {
+		t.Error("redirect to an undeclared host was followed")
+	}
+
+	// The configuration can restrict the hosts a plugin declared
+	f.limits.FetchHosts = []string{"*.example.com"}
+	if _, err := f.fetch(srv.URL, nil); err == nil {
+		t.Error("request to a host that isn't allowed by the configuration succeeded")
+	}
+}
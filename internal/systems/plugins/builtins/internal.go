@@ -7,20 +7,41 @@ import (
 	"go.elara.ws/owobot/internal/systems/tickets"
 )
 
-type eventLogAPI struct{}
+type eventLogAPI struct {
+	allowed bool
+	guilds  Guilds
+}
 
-func (eventLogAPI) Log(s *discordgo.Session, guildID string, e eventlog.Entry) error {
-	return eventlog.Log(s, guildID, e)
+func (e eventLogAPI) Log(s *discordgo.Session, guildID string, entry eventlog.Entry) error {
+	if !e.allowed {
+		return missingCapability("eventLog")
+	} else if err := e.guilds.check(guildID); err != nil {
+		return err
+	}
+	return eventlog.Log(s, guildID, entry)
 }
 
-type ticketsAPI struct{}
+type ticketsAPI struct {
+	allowed bool
+	guilds  Guilds
+}
 
-func (ticketsAPI) Open(s *discordgo.Session, guildID string, user, executor *discordgo.User) (string, error) {
+func (t ticketsAPI) Open(s *discordgo.Session, guildID string, user, executor *discordgo.User) (string, error) {
+	if !t.allowed {
+		return "", missingCapability("tickets")
+	} else if err := t.guilds.check(guildID); err != nil {
+		return "", err
+	}
 	return tickets.Open(s, guildID, user, executor)
 }
 
-func (ticketsAPI) Close(s *discordgo.Session, guildID string, user, executor *discordgo.User) {
-	tickets.Close(s, guildID, user, executor)
+func (t ticketsAPI) Close(s *discordgo.Session, guildID string, user, executor *discordgo.User) error {
+	if !t.allowed {
+		return missingCapability("tickets")
+	} else if err := t.guilds.check(guildID); err != nil {
+		return err
+	}
+	return tickets.Close(s, guildID, user, executor)
 }
 
 type cacheAPI struct{}
@@ -0,0 +1,92 @@
+package builtins
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.elara.ws/owobot/internal/db"
+)
+
+// KVEntry represents a key/value pair returned by kv.list
+type KVEntry struct {
+	Key   string
+	Value any
+}
+
+// kvAPI stores data for a plugin, scoped by guild. Only the guilds
+// the plugin is enabled in may be accessed, so that plugins can't
+// read or change data in guilds that haven't enabled them.
+type kvAPI struct {
+	pluginName string
+	guilds     Guilds
+}
+
+// Get returns the value stored at key in the given guild, or null if there isn't one.
+func (k kvAPI) Get(guildID, key string) (any, error) {
+	if err := k.guilds.enabled(guildID); err != nil {
+		return nil, err
+	}
+
+	value, err := db.KVGet(k.pluginName, guildID, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var out any
+	return out, json.Unmarshal([]byte(value), &out)
+}
+
+// Set stores value at key in the given guild. If ttl is greater than zero,
+// the value expires after that many seconds.
+func (k kvAPI) Set(guildID, key string, value any, ttl int64) error {
+	if err := k.guilds.enabled(guildID); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(time.Duration(ttl) * time.Second)
+	}
+
+	return db.KVSet(k.pluginName, guildID, key, string(data), expires)
+}
+
+// Delete removes the value stored at key in the given guild
+func (k kvAPI) Delete(guildID, key string) error {
+	if err := k.guilds.enabled(guildID); err != nil {
+		return err
+	}
+
+	return db.KVDelete(k.pluginName, guildID, key)
+}
+
+// List returns all the entries in the given guild whose keys start with prefix
+func (k kvAPI) List(guildID, prefix string) ([]KVEntry, error) {
+	if err := k.guilds.enabled(guildID); err != nil {
+		return nil, err
+	}
+
+	entries, err := db.KVList(k.pluginName, guildID, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]KVEntry, len(entries))
+	for i, entry := range entries {
+		out[i].Key = entry.Key
+		err = json.Unmarshal([]byte(entry.Value), &out[i].Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
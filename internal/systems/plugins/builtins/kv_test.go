@@ -0,0 +1,42 @@
+package builtins
+
+import (
+	"context"
+	"testing"
+
+	"go.elara.ws/owobot/internal/db"
+)
+
+func TestKVRequiresEnabledGuild(t *testing.T) {
+	err := db.Init(context.Background(), "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	k := kvAPI{pluginName: "p", guilds: Guilds{Enabled: func(guildID string) bool { return guildID == "1" }}}
+
+	err = k.Set("1", "key", map[string]any{"a": 1.0}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := k.Get("1", "key")
+	if err != nil {
+		t.Fatal(err)
+	} else if m, ok := value.(map[string]any); !ok || m["a"] != 1.0 {
+		t.Errorf("expected the value to round-trip, got %#v", value)
+	}
+
+	if err := k.Set("2", "key", 1, 0); err == nil {
+		t.Error("set succeeded in a guild the plugin isn't enabled in")
+	}
+	if _, err := k.Get("2", "key"); err == nil {
+		t.Error("get succeeded in a guild the plugin isn't enabled in")
+	}
+	if _, err := k.List("2", ""); err == nil {
+		t.Error("list succeeded in a guild the plugin isn't enabled in")
+	}
+	if err := k.Delete("2", "key"); err == nil {
+		t.Error("delete succeeded in a guild the plugin isn't enabled in")
+	}
+}
@@ -0,0 +1,37 @@
+package builtins
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLimitExceeded is returned by builtins when a plugin exceeds one of its resource limits
+var ErrLimitExceeded = errors.New("plugin exceeded a resource limit")
+
+// Limits contains the resource limits that apply to a plugin's use of the builtin APIs.
+// Zero values disable the corresponding limit.
+type Limits struct {
+	// FetchTimeout is the maximum amount of time a fetch request may take
+	FetchTimeout time.Duration
+	// FetchMaxBodySize is the maximum size in bytes of a fetched response body
+	FetchMaxBodySize int64
+	// FetchMaxCookies is the maximum amount of cookies the plugin's cookie jar may store
+	FetchMaxCookies int
+	// FetchHosts restricts the hosts the plugin may make requests to, in addition
+	// to the ones it declared in its capabilities. A nil value doesn't restrict them.
+	FetchHosts []string
+	// SQLMaxRows is the maximum amount of rows a single query may return
+	SQLMaxRows int
+	// OnExceeded is called whenever the plugin exceeds one of the limits
+	OnExceeded func(err error)
+}
+
+// exceeded returns an error wrapping [ErrLimitExceeded] and reports it to OnExceeded
+func (l Limits) exceeded(format string, args ...any) error {
+	err := fmt.Errorf("%w: %s", ErrLimitExceeded, fmt.Sprintf(format, args...))
+	if l.OnExceeded != nil {
+		l.OnExceeded(err)
+	}
+	return err
+}
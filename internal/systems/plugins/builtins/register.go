@@ -2,18 +2,108 @@ package builtins
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
 
 	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/eventloop"
+	"go.elara.ws/owobot/internal/db"
 )
 
 // Register registers all the owobot APIs in JavaScript.
-func Register(vm *goja.Runtime, pluginName, pluginVersion string) error {
-	return errors.Join(
-		vm.GlobalObject().Set("sql", sqlAPI{pluginName: pluginName}),
-		vm.GlobalObject().Set("vercmp", vercmpAPI{}),
-		vm.GlobalObject().Set("cache", cacheAPI{}),
-		vm.GlobalObject().Set("tickets", ticketsAPI{}),
-		vm.GlobalObject().Set("eventlog", eventLogAPI{}),
-		vm.GlobalObject().Set("fetch", fetch(pluginName, pluginVersion)),
-	)
+// Privileged APIs are restricted according to the capabilities
+// declared in the plugin's info, and resource usage is restricted
+// according to the given limits, and to the guilds allowed by guilds.
+// Asynchronous APIs deliver their results using the given event loop.
+// The returned function releases the resources held by the APIs, such
+// as prepared statements, and must be called when the plugin is unloaded.
+func Register(vm *goja.Runtime, loop *eventloop.EventLoop, info db.PluginInfo, limits Limits, guilds Guilds) (func(), error) {
+	stmts := &stmtSet{stmts: map[*sqlStmt]struct{}{}}
+
+	var errs []error
+	for name, value := range globals(vm, info, limits, guilds, stmts) {
+		errs = append(errs, vm.GlobalObject().Set(name, value))
+	}
+	errs = append(errs, registerFetch(vm, loop, info, limits))
+	return stmts.closeAll, errors.Join(errs...)
+}
+
+// globals returns the global objects registered by [Register]
+func globals(vm *goja.Runtime, info db.PluginInfo, limits Limits, guilds Guilds, stmts *stmtSet) map[string]any {
+	caps := info.Capabilities
+	return map[string]any{
+		"sql": sqlAPI{
+			vm:          vm,
+			pluginName:  info.Name,
+			allowed:     caps.SQL,
+			guildTables: info.GuildTables,
+			guilds:      guilds,
+			limits:      limits,
+			stmts:       stmts,
+		},
+		"kv":       kvAPI{pluginName: info.Name, guilds: guilds},
+		"vercmp":   vercmpAPI{},
+		"cache":    cacheAPI{},
+		"tickets":  ticketsAPI{allowed: caps.Tickets, guilds: guilds},
+		"eventlog": eventLogAPI{allowed: caps.EventLog, guilds: guilds},
+	}
+}
+
+// Globals returns values with the types of all the globals registered by [Register],
+// so that they can be reflected on. Properties of global functions use dotted names,
+// such as "fetch.async", and constructors are represented by the type they construct.
+// The values are only meant for reflection, and must never be called.
+func Globals() map[string]any {
+	out := globals(nil, db.PluginInfo{}, Limits{}, Guilds{}, nil)
+	f := &fetcher{}
+	out["fetch"] = f.fetch
+	out["fetch.async"] = f.fetchAsync
+	out["fetch.clearCookies"] = (&boundedJar{}).Clear
+	out["AbortController"] = reflect.TypeOf(AbortController{})
+	return out
+}
+
+// Guilds restricts the guilds the APIs may act on
+type Guilds struct {
+	// Enabled checks whether the plugin is enabled in the given guild.
+	// APIs that store per-guild data refuse guilds where it isn't.
+	// A nil value allows every guild.
+	Enabled func(guildID string) bool
+	// Check returns an error if the privileged APIs may not act on the given
+	// guild, such as because the plugin isn't enabled there. A nil value
+	// allows every guild.
+	Check func(guildID string) error
+	// Current returns the ID of the guild whose event or command is
+	// currently being handled, or an empty string if there isn't one.
+	Current func() string
+}
+
+// current calls Current if it's set
+func (g Guilds) current() string {
+	if g.Current == nil {
+		return ""
+	}
+	return g.Current()
+}
+
+// enabled returns an error if the plugin isn't enabled in the given guild
+func (g Guilds) enabled(guildID string) error {
+	if g.Enabled != nil && !g.Enabled(guildID) {
+		return fmt.Errorf("plugin is not enabled in guild %q", guildID)
+	}
+	return nil
+}
+
+// check calls Check if it's set
+func (g Guilds) check(guildID string) error {
+	if g.Check == nil {
+		return nil
+	}
+	return g.Check(guildID)
+}
+
+// missingCapability returns an error for a call to an API that
+// requires a capability the plugin hasn't declared.
+func missingCapability(name string) error {
+	return fmt.Errorf("plugin did not declare the %q capability", name)
 }
@@ -1,42 +1,234 @@
 package builtins
 
 import (
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/dop251/goja"
 	"github.com/jmoiron/sqlx"
 	"go.elara.ws/owobot/internal/db"
 	"go.elara.ws/owobot/internal/db/sqltabler"
 )
 
+// sqlExt is implemented by both [sqlx.DB] and [sqlx.Tx]
+type sqlExt interface {
+	sqlx.Ext
+	Preparex(query string) (*sqlx.Stmt, error)
+}
+
 type sqlAPI struct {
-	pluginName string
+	vm          *goja.Runtime
+	pluginName  string
+	allowed     bool
+	guildTables bool
+	guildID     string
+	guilds      Guilds
+	limits      Limits
+	// tx is the transaction this API is bound to, if any
+	tx *sqlx.Tx
+	// stmts contains the plugin's open prepared statements
+	stmts *stmtSet
+}
+
+// ext returns the transaction if the API is bound to one,
+// or the database otherwise.
+func (s sqlAPI) ext() sqlExt {
+	if s.tx != nil {
+		return s.tx
+	}
+	return db.DB()
+}
+
+// modifyQuery checks that the plugin is allowed to run query,
+// and rewrites it to use the plugin's tables.
+func (s sqlAPI) modifyQuery(query string) (string, error) {
+	if !s.allowed {
+		return "", missingCapability("sql")
+	}
+	if s.guildTables && s.guildID == "" {
+		return "", errors.New("plugin uses per-guild tables, queries must be made with sql.guild(guildID)")
+	}
+	return ModifyQuery(s.pluginName, s.guildID, query)
 }
 
 func (s sqlAPI) Exec(query string, args ...any) error {
-	newQuery, err := sqltabler.Modify(query, "_owobot_plugin_", "_"+s.pluginName)
+	newQuery, err := s.modifyQuery(query)
 	if err != nil {
 		return err
 	}
-	_, err = db.DB().Exec(newQuery, args...)
+	_, err = s.ext().Exec(newQuery, args...)
 	return err
 }
 
 func (s sqlAPI) Query(query string, args ...any) ([]map[string]any, error) {
-	newQuery, err := sqltabler.Modify(query, "_owobot_plugin_", "_"+s.pluginName)
+	newQuery, err := s.modifyQuery(query)
 	if err != nil {
 		return nil, err
 	}
-	rows, err := db.DB().Queryx(newQuery, args...)
+	rows, err := s.ext().Queryx(newQuery, args...)
 	if err != nil {
 		return nil, err
 	}
-	return rowsToMap(rows)
+	return s.rowsToMap(rows)
 }
 
 func (s sqlAPI) QueryOne(query string, args ...any) (map[string]any, error) {
-	newQuery, err := sqltabler.Modify(query, "_owobot_plugin_", "_"+s.pluginName)
+	newQuery, err := s.modifyQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return rowToMap(s.ext().QueryRowx(newQuery, args...))
+}
+
+// Guild returns a version of the SQL API that uses the given guild's tables.
+// It must be used by plugins with per-guild tables. If no guild ID is given,
+// the guild whose event or command is currently being handled is used. Only
+// the tables of guilds the plugin is enabled in may be used.
+func (s sqlAPI) Guild(guildID string) (sqlAPI, error) {
+	if guildID == "" {
+		guildID = s.guilds.current()
+		if guildID == "" {
+			return sqlAPI{}, errors.New("no guild ID given outside of a guild event or command")
+		}
+	}
+
+	if !s.guildTables {
+		return sqlAPI{}, errors.New("plugin doesn't use per-guild tables")
+	} else if _, err := strconv.ParseUint(guildID, 10, 64); err != nil {
+		// Guild IDs are part of table names, so anything other than
+		// a snowflake could be used to reach another plugin's tables.
+		return sqlAPI{}, errors.New("invalid guild ID")
+	} else if err := s.guilds.enabled(guildID); err != nil {
+		return sqlAPI{}, err
+	}
+
+	s.guildID = guildID
+	return s, nil
+}
+
+// Transaction calls fn with a version of the SQL API bound to a new transaction.
+// If fn throws an exception, the transaction is rolled back. Otherwise, it's
+// committed. The value returned by fn is returned.
+func (s sqlAPI) Transaction(fn goja.Value) (goja.Value, error) {
+	if !s.allowed {
+		return nil, missingCapability("sql")
+	} else if s.tx != nil {
+		return nil, errors.New("nested transactions are not supported")
+	}
+
+	callable, ok := goja.AssertFunction(fn)
+	if !ok {
+		return nil, errors.New("value passed to sql.transaction is not a function")
+	}
+
+	tx, err := db.DB().Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	s.tx = tx
+	ret, err := callable(goja.Undefined(), s.vm.ToValue(s))
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, tx.Commit()
+}
+
+// Prepare creates a prepared statement that can be executed multiple times.
+// Statements prepared within a transaction can only be used until it ends.
+func (s sqlAPI) Prepare(query string) (*sqlStmt, error) {
+	newQuery, err := s.modifyQuery(query)
 	if err != nil {
 		return nil, err
 	}
-	row := db.DB().QueryRowx(newQuery, args...)
+	stmt, err := s.ext().Preparex(newQuery)
+	if err != nil {
+		return nil, err
+	}
+	ss := &sqlStmt{api: s, stmt: stmt}
+	// Statements prepared within a transaction are closed when it ends
+	if s.tx == nil {
+		s.stmts.add(ss)
+	}
+	return ss, nil
+}
+
+// sqlStmt represents a prepared statement
+type sqlStmt struct {
+	api  sqlAPI
+	stmt *sqlx.Stmt
+}
+
+// stmtSet keeps track of a plugin's open prepared statements,
+// so that they can be closed when the plugin is unloaded.
+type stmtSet struct {
+	mu    sync.Mutex
+	stmts map[*sqlStmt]struct{}
+}
+
+func (ss *stmtSet) add(stmt *sqlStmt) {
+	ss.mu.Lock()
+	ss.stmts[stmt] = struct{}{}
+	ss.mu.Unlock()
+}
+
+func (ss *stmtSet) remove(stmt *sqlStmt) {
+	ss.mu.Lock()
+	delete(ss.stmts, stmt)
+	ss.mu.Unlock()
+}
+
+// closeAll closes all the statements in the set
+func (ss *stmtSet) closeAll() {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	for stmt := range ss.stmts {
+		stmt.stmt.Close()
+		delete(ss.stmts, stmt)
+	}
+}
+
+func (ss *sqlStmt) Exec(args ...any) error {
+	_, err := ss.stmt.Exec(args...)
+	return err
+}
+
+func (ss *sqlStmt) Query(args ...any) ([]map[string]any, error) {
+	rows, err := ss.stmt.Queryx(args...)
+	if err != nil {
+		return nil, err
+	}
+	return ss.api.rowsToMap(rows)
+}
+
+func (ss *sqlStmt) QueryOne(args ...any) (map[string]any, error) {
+	return rowToMap(ss.stmt.QueryRowx(args...))
+}
+
+// Close releases the resources used by the prepared statement
+func (ss *sqlStmt) Close() error {
+	ss.api.stmts.remove(ss)
+	return ss.stmt.Close()
+}
+
+// ModifyQuery rewrites a plugin's SQL query so that it only uses tables
+// that belong to the plugin. If guildID isn't empty, the tables that
+// belong to the plugin in that guild are used.
+func ModifyQuery(pluginName, guildID, query string) (string, error) {
+	if guildID == "" {
+		return sqltabler.Modify(query, "_owobot_plugin_", "_"+pluginName)
+	}
+	// Per-guild tables use a different prefix, so that they can never have
+	// the same name as another plugin's shared tables. For example, plugin
+	// "foo" in guild 1 and plugin "foo_1" would otherwise share tables.
+	return sqltabler.Modify(query, "_owobot_guild_plugin_", "_"+pluginName+"_"+guildID)
+}
+
+// rowToMap scans a single row into a map
+func rowToMap(row *sqlx.Row) (map[string]any, error) {
 	if err := row.Err(); err != nil {
 		return nil, err
 	}
@@ -44,9 +236,17 @@ func (s sqlAPI) QueryOne(query string, args ...any) (map[string]any, error) {
 	return out, row.MapScan(out)
 }
 
-func rowsToMap(rows *sqlx.Rows) ([]map[string]any, error) {
+// rowsToMap scans all the given rows into maps, returning an error
+// if there are more rows than the plugin is allowed to query.
+func (s sqlAPI) rowsToMap(rows *sqlx.Rows) ([]map[string]any, error) {
+	defer rows.Close()
+
 	var out []map[string]any
 	for rows.Next() {
+		if s.limits.SQLMaxRows > 0 && len(out) >= s.limits.SQLMaxRows {
+			return nil, s.limits.exceeded("query returned more than %d rows", s.limits.SQLMaxRows)
+		}
+
 		resultMap := map[string]any{}
 		err := rows.MapScan(resultMap)
 		if err != nil {
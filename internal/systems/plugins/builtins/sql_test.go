@@ -0,0 +1,46 @@
+package builtins
+
+import "testing"
+
+func TestSQLGuild(t *testing.T) {
+	current := ""
+	s := sqlAPI{
+		pluginName:  "p",
+		allowed:     true,
+		guildTables: true,
+		guilds: Guilds{
+			Enabled: func(guildID string) bool { return guildID == "1" || guildID == "2" },
+			Current: func() string { return current },
+		},
+	}
+
+	if _, err := s.Guild(""); err == nil {
+		t.Error("sql.guild succeeded without a guild ID outside of a guild event")
+	}
+
+	current = "2"
+	gs, err := s.Guild("")
+	if err != nil {
+		t.Fatal(err)
+	} else if gs.guildID != "2" {
+		t.Errorf("expected the current guild to be used, got %q", gs.guildID)
+	}
+
+	gs, err = s.Guild("1")
+	if err != nil {
+		t.Fatal(err)
+	} else if gs.guildID != "1" {
+		t.Errorf("expected the given guild to be used, got %q", gs.guildID)
+	}
+
+	for _, guildID := range []string{"3", "1_other", "1 OR 1"} {
+		if _, err := s.Guild(guildID); err == nil {
+			t.Errorf("sql.guild(%q) succeeded", guildID)
+		}
+	}
+
+	s.guildTables = false
+	if _, err := s.Guild("1"); err == nil {
+		t.Error("sql.guild succeeded for a plugin without per-guild tables")
+	}
+}
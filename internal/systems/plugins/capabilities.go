@@ -0,0 +1,274 @@
+package plugins
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/logger/log"
+	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/shards"
+	"go.elara.ws/owobot/internal/systems/eventlog"
+)
+
+// moderationEndpoints matches the discord API endpoints that can only be
+// used by plugins with the moderation capability, by HTTP method. The
+// first submatch of each expression is the ID of the guild being moderated.
+var moderationEndpoints = map[string][]*regexp.Regexp{
+	http.MethodPatch: {
+		regexp.MustCompile(`^/api/v\d+/guilds/(\d+)/members/\d+$`),
+	},
+	http.MethodDelete: {
+		regexp.MustCompile(`^/api/v\d+/guilds/(\d+)/members/\d+$`),
+		regexp.MustCompile(`^/api/v\d+/guilds/(\d+)/members/\d+/roles/\d+$`),
+		regexp.MustCompile(`^/api/v\d+/guilds/(\d+)/bans/\d+$`),
+	},
+	http.MethodPut: {
+		regexp.MustCompile(`^/api/v\d+/guilds/(\d+)/members/\d+/roles/\d+$`),
+		regexp.MustCompile(`^/api/v\d+/guilds/(\d+)/bans/\d+$`),
+	},
+	http.MethodPost: {
+		regexp.MustCompile(`^/api/v\d+/guilds/(\d+)/bulk-ban$`),
+		regexp.MustCompile(`^/api/v\d+/guilds/(\d+)/prune$`),
+	},
+}
+
+// apiHost is the host of the discord API, which the bot's token is sent to
+var apiHost = func() string {
+	u, err := url.Parse(discordgo.EndpointAPI)
+	if err != nil {
+		panic(err)
+	}
+	return u.Host
+}()
+
+// capabilityTransport is an HTTP transport that refuses discord API
+// requests which require capabilities a plugin hasn't declared. The
+// sessions given to plugins don't contain the bot's token, so this
+// transport adds it to requests to the discord API.
+type capabilityTransport struct {
+	// base is the session the plugin's session was made from. Its transport
+	// is looked up on every request rather than copied, since it may be
+	// replaced after the plugin's session was created, such as by the
+	// HTTP interactions endpoint.
+	base *discordgo.Session
+	caps db.PluginCapabilities
+	// checkGuild returns an error if the plugin may not
+	// moderate the given guild. A nil value allows every guild.
+	checkGuild func(guildID string) error
+}
+
+// next returns the transport requests are sent through once they've been checked
+func (ct capabilityTransport) next() http.RoundTripper {
+	if ct.base.Client != nil && ct.base.Client.Transport != nil {
+		return ct.base.Client.Transport
+	}
+	return http.DefaultTransport
+}
+
+func (ct capabilityTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for _, re := range moderationEndpoints[req.Method] {
+		match := re.FindStringSubmatch(req.URL.Path)
+		if match == nil {
+			continue
+		}
+
+		if !ct.caps.Moderation {
+			return nil, fmt.Errorf("plugin did not declare the %q capability", "moderation")
+		} else if ct.checkGuild != nil {
+			if err := ct.checkGuild(match[1]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// The token is only ever sent to the discord API, since plugins
+	// can use the session's client to make requests to any URL.
+	if req.URL.Scheme == "https" && req.URL.Host == apiHost && ct.base.Token != "" {
+		// RoundTrippers must not modify the request they're given
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", ct.base.Token)
+	}
+
+	return ct.next().RoundTrip(req)
+}
+
+// restrictSession returns a session for a plugin to use. It shares its state
+// and rate limiter with s, but refuses API requests that require capabilities
+// the plugin hasn't declared, or that moderate guilds checkGuild refuses. The
+// bot's token is removed from the returned session, so that plugins can't read
+// it and bypass the restrictions.
+func restrictSession(s *discordgo.Session, caps db.PluginCapabilities, checkGuild func(string) error) *discordgo.Session {
+	if s == nil {
+		return s
+	}
+
+	client := &http.Client{Transport: capabilityTransport{base: s, caps: caps, checkGuild: checkGuild}}
+	if s.Client != nil {
+		client.Timeout = s.Client.Timeout
+	}
+
+	identify := s.Identify
+	identify.Token = ""
+
+	return &discordgo.Session{
+		State:                  s.State,
+		StateEnabled:           s.StateEnabled,
+		Ratelimiter:            s.Ratelimiter,
+		ShouldRetryOnRateLimit: s.ShouldRetryOnRateLimit,
+		MaxRestRetries:         s.MaxRestRetries,
+		UserAgent:              s.UserAgent,
+		Identify:               identify,
+		ShardID:                s.ShardID,
+		ShardCount:             s.ShardCount,
+		LogLevel:               s.LogLevel,
+		Client:                 client,
+	}
+}
+
+// session returns the restricted version of s for this plugin, creating it if needed
+func (oa *owobotAPI) session(s *discordgo.Session) *discordgo.Session {
+	oa.mu.Lock()
+	defer oa.mu.Unlock()
+
+	if oa.sessions == nil {
+		oa.sessions = map[*discordgo.Session]*discordgo.Session{}
+	}
+
+	rs, ok := oa.sessions[s]
+	if !ok {
+		rs = restrictSession(s, oa.PluginInfo.Capabilities, oa.checkGuild)
+		oa.sessions[s] = rs
+	}
+	return rs
+}
+
+// checkGuild returns an error if the plugin isn't enabled in the given guild or
+// if the guild's admins haven't approved the capabilities it currently has.
+// Privileged APIs check the guilds they act on, so that plugins can't use
+// their capabilities in guilds that never agreed to them.
+func (oa *owobotAPI) checkGuild(guildID string) error {
+	name := oa.PluginInfo.Name
+	if !pluginEnabled(guildID, name) {
+		return fmt.Errorf("plugin %q is not enabled in guild %q", name, guildID)
+	}
+
+	approved, ok, err := db.PluginApproval(name, guildID)
+	if err != nil {
+		return err
+	} else if !ok || capabilitiesGrew(approved, oa.PluginInfo.Capabilities) {
+		return fmt.Errorf("the capabilities of plugin %q have not been approved in guild %q", name, guildID)
+	}
+
+	return nil
+}
+
+// describeCapabilities returns a human-readable list of the given capabilities
+func describeCapabilities(caps db.PluginCapabilities) string {
+	var lines []string
+	if len(caps.Hosts) > 0 {
+		lines = append(lines, "- Make network requests to: `"+strings.Join(caps.Hosts, "`, `")+"`")
+	}
+	if caps.SQL {
+		lines = append(lines, "- Store data in the SQL database")
+	}
+	if caps.Tickets {
+		lines = append(lines, "- Open and close tickets")
+	}
+	if caps.EventLog {
+		lines = append(lines, "- Write to the event log")
+	}
+	if caps.Moderation {
+		lines = append(lines, "- Kick, ban, time out, and change the roles of members")
+	}
+
+	if len(lines) == 0 {
+		return "This plugin doesn't require any privileged capabilities."
+	}
+	return "This plugin requires the following capabilities:\n" + strings.Join(lines, "\n")
+}
+
+// capabilitiesHash returns a short hash of the given capabilities, used to make
+// sure the capabilities a user approved are the ones a plugin currently has.
+func capabilitiesHash(caps db.PluginCapabilities) string {
+	data, err := json.Marshal(caps)
+	if err != nil {
+		// PluginCapabilities only contains booleans and strings, so this can't happen
+		panic(err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// capabilitiesGrew checks whether caps contains any capabilities or hosts that aren't in approved
+func capabilitiesGrew(approved, caps db.PluginCapabilities) bool {
+	if (caps.SQL && !approved.SQL) ||
+		(caps.Tickets && !approved.Tickets) ||
+		(caps.EventLog && !approved.EventLog) ||
+		(caps.Moderation && !approved.Moderation) {
+		return true
+	}
+
+	for _, host := range caps.Hosts {
+		if !slices.ContainsFunc(approved.Hosts, func(h string) bool { return strings.EqualFold(h, host) }) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// revokeGrownCapabilities disables a newly loaded plugin in every guild that
+// approved fewer capabilities than it now requires, so that the new version
+// never runs with capabilities the guild's admins haven't seen. They can
+// review and approve the new capabilities by enabling the plugin again.
+func revokeGrownCapabilities(s *discordgo.Session, plugin *Plugin) {
+	for _, guildID := range guildsWithPlugins(plugin.Info.Name) {
+		if !shards.Local(guildID) {
+			continue
+		}
+
+		approved, ok, err := db.PluginApproval(plugin.Info.Name, guildID)
+		if err != nil {
+			log.Warn("Error getting approved plugin capabilities").Str("plugin", plugin.Info.Name).Str("guild-id", guildID).Err(err).Send()
+			continue
+		} else if !ok {
+			// The plugin was enabled before approvals were stored,
+			// so the capabilities it has now are assumed to be approved.
+			err = db.SetPluginApproval(plugin.Info.Name, guildID, plugin.Info.Capabilities)
+			if err != nil {
+				log.Warn("Error storing approved plugin capabilities").Str("plugin", plugin.Info.Name).Str("guild-id", guildID).Err(err).Send()
+			}
+			continue
+		} else if !capabilitiesGrew(approved, plugin.Info.Capabilities) {
+			continue
+		}
+
+		log.Warn("Plugin requires capabilities that weren't approved, disabling").Str("plugin", plugin.Info.Name).Str("guild-id", guildID).Send()
+
+		err = disable(s, guildID, plugin)
+		if err != nil {
+			log.Warn("Error disabling plugin").Str("plugin", plugin.Info.Name).Str("guild-id", guildID).Err(err).Send()
+		}
+
+		err = eventlog.Log(s, guildID, eventlog.Entry{
+			Title: "Plugin disabled",
+			Description: fmt.Sprintf(
+				"The %q plugin was disabled because version %s requires capabilities that weren't approved. Enable it again to review them.\n\n%s",
+				plugin.Info.Name,
+				plugin.Info.Version,
+				describeCapabilities(plugin.Info.Capabilities),
+			),
+		})
+		if err != nil {
+			log.Warn("Error writing plugin notice to event log").Str("guild-id", guildID).Err(err).Send()
+		}
+	}
+}
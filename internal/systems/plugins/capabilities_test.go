@@ -0,0 +1,131 @@
+package plugins
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/owobot/internal/db"
+)
+
+// recordTransport records the last request it was given
+type recordTransport struct {
+	req *http.Request
+}
+
+func (rt *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.req = req
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestRestrictSessionHidesToken(t *testing.T) {
+	s, _ := discordgo.New("Bot secret")
+	rt := &recordTransport{}
+	s.Client.Transport = rt
+
+	for _, caps := range []db.PluginCapabilities{{}, {Moderation: true}} {
+		rs := restrictSession(s, caps, nil)
+		if rs.Token != "" || rs.Identify.Token != "" {
+			t.Fatalf("restricted session contains the token (capabilities %+v)", caps)
+		}
+
+		_, err := rs.Client.Get(discordgo.EndpointUser("@me"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if auth := rt.req.Header.Get("Authorization"); auth != "Bot secret" {
+			t.Errorf("expected the token to be added to API requests, got %q", auth)
+		}
+
+		_, err = rs.Client.Get("https://example.com/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if auth := rt.req.Header.Get("Authorization"); auth != "" {
+			t.Errorf("token was sent to another host: %q", auth)
+		}
+	}
+}
+
+func TestCapabilitiesGrew(t *testing.T) {
+	approved := db.PluginCapabilities{SQL: true, Hosts: []string{"example.com"}}
+
+	cases := []struct {
+		caps     db.PluginCapabilities
+		expected bool
+	}{
+		{db.PluginCapabilities{}, false},
+		{db.PluginCapabilities{SQL: true, Hosts: []string{"EXAMPLE.com"}}, false},
+		{db.PluginCapabilities{Moderation: true}, true},
+		{db.PluginCapabilities{Hosts: []string{"example.com", "evil.com"}}, true},
+		{db.PluginCapabilities{Hosts: []string{"*"}}, true},
+	}
+
+	for _, c := range cases {
+		if got := capabilitiesGrew(approved, c.caps); got != c.expected {
+			t.Errorf("capabilitiesGrew(%+v, %+v) = %t, expected %t", approved, c.caps, got, c.expected)
+		}
+	}
+}
+
+func TestRestrictSessionUsesCurrentTransport(t *testing.T) {
+	s, _ := discordgo.New("Bot secret")
+	s.Client.Transport = &recordTransport{}
+	rs := restrictSession(s, db.PluginCapabilities{}, nil)
+
+	// The transport is replaced after the plugin's session was created,
+	// the same way the HTTP interactions endpoint does it.
+	replaced := &recordTransport{}
+	s.Client.Transport = replaced
+
+	_, err := rs.Client.Get(discordgo.EndpointUser("@me"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replaced.req == nil {
+		t.Error("request wasn't sent through the session's current transport")
+	}
+}
+
+func TestModerationChecksGuild(t *testing.T) {
+	s, _ := discordgo.New("Bot secret")
+	rt := &recordTransport{}
+	s.Client.Transport = rt
+
+	checkGuild := func(guildID string) error {
+		if guildID != "1" {
+			return errors.New("not enabled")
+		}
+		return nil
+	}
+
+	cases := []struct {
+		caps    db.PluginCapabilities
+		guildID string
+		allowed bool
+	}{
+		{db.PluginCapabilities{Moderation: true}, "1", true},
+		{db.PluginCapabilities{Moderation: true}, "2", false},
+		{db.PluginCapabilities{}, "1", false},
+	}
+
+	for _, c := range cases {
+		rs := restrictSession(s, c.caps, checkGuild)
+		rt.req = nil
+		err := rs.GuildBanCreate(c.guildID, "3", 0)
+		if allowed := err == nil && rt.req != nil; allowed != c.allowed {
+			t.Errorf("banning in guild %s with capabilities %+v: allowed = %t, expected %t (%v)", c.guildID, c.caps, allowed, c.allowed, err)
+		}
+	}
+}
+
+func TestCapabilitiesHash(t *testing.T) {
+	caps := db.PluginCapabilities{SQL: true, Hosts: []string{"example.com"}}
+	if capabilitiesHash(caps) != capabilitiesHash(db.PluginCapabilities{SQL: true, Hosts: []string{"example.com"}}) {
+		t.Error("equal capabilities have different hashes")
+	}
+	if capabilitiesHash(caps) == capabilitiesHash(db.PluginCapabilities{SQL: true, Hosts: []string{"example.com", "evil.com"}}) {
+		t.Error("different capabilities have the same hash")
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/dop251/goja"
 	"github.com/kballard/go-shellquote"
+	"go.elara.ws/owobot/internal/db"
 	"go.elara.ws/owobot/internal/util"
 )
 
@@ -21,15 +23,26 @@ func pluginadmCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 		return enableCmd(s, i)
 	case "disable":
 		return disableCmd(s, i)
+	case "reload":
+		return reloadCmd(s, i)
+	case "config":
+		return configCmd(s, i)
+	case "status":
+		return statusCmd(s, i)
+	case "errorlog":
+		return errorLogCmd(s, i)
+	case "install":
+		return installCmd(s, i)
 	default:
 		return fmt.Errorf("unknown pluginadm subcommand: %s", name)
 	}
 }
 
-// listCmd handles the `/plugin list` command.
+// listCmd handles the `/plugin list` command. Plugins that require intents
+// owobot isn't receiving events for are listed with a warning.
 func listCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	sb := strings.Builder{}
-	for _, plugin := range Plugins {
+	for _, plugin := range allPlugins() {
 		sb.WriteString(plugin.Info.Name)
 		sb.WriteString(" (")
 		sb.WriteString(plugin.Info.Version)
@@ -40,11 +53,25 @@ func listCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 			sb.WriteString(" *")
 		}
 		sb.WriteByte('\n')
+
+		missing := missingIntents(s, plugin)
+		if disallowed := disallowedIntents(missing); disallowed != 0 {
+			sb.WriteString("  ⚠️ Requires privileged intents that aren't allowed by the bot's configuration: ")
+			sb.WriteString(intentNames(disallowed))
+			sb.WriteByte('\n')
+			missing &^= disallowed
+		}
+		if missing != 0 {
+			sb.WriteString("  ⚠️ Requires intents that will only be enabled once owobot restarts: ")
+			sb.WriteString(intentNames(missing))
+			sb.WriteByte('\n')
+		}
 	}
 	return util.RespondEphemeral(s, i.Interaction, sb.String())
 }
 
-// enableCmd handles the `/plugin enable` command.
+// enableCmd handles the `/plugin enable` command. It shows the capabilities the
+// plugin requires and asks for confirmation before actually enabling it.
 func enableCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	data := i.ApplicationCommandData()
 	pluginName := data.Options[0].Options[0].StringValue()
@@ -54,28 +81,87 @@ func enableCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 		return fmt.Errorf("no such plugin: %q", pluginName)
 	}
 
-	err := enablePlugin(i.GuildID, pluginName)
+	if pluginEnabled(i.GuildID, pluginName) {
+		return fmt.Errorf("plugin %q is already enabled", pluginName)
+	}
+
+	if missing := missingDependencies(i.GuildID, plugin); len(missing) > 0 {
+		return fmt.Errorf("plugin %q depends on plugins that aren't enabled: %s", pluginName, strings.Join(missing, ", "))
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       fmt.Sprintf("Enable %s (%s)?", plugin.Info.Name, plugin.Info.Version),
+				Description: describeCapabilities(plugin.Info.Capabilities),
+			}},
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Enable",
+						Style:    discordgo.SuccessButton,
+						CustomID: "pluginadm-enable:" + capabilitiesHash(plugin.Info.Capabilities) + ":" + plugin.Info.Name,
+					},
+					discordgo.Button{
+						Label:    "Cancel",
+						Style:    discordgo.SecondaryButton,
+						CustomID: "pluginadm-cancel",
+					},
+				}},
+			},
+		},
+	})
+}
+
+// enable enables a plugin in the given guild, publishes its commands,
+// and calls its onEnable hook.
+func enable(s *discordgo.Session, guildID string, plugin *Plugin) error {
+	if missing := missingDependencies(guildID, plugin); len(missing) > 0 {
+		return fmt.Errorf("plugin %q depends on plugins that aren't enabled: %s", plugin.Info.Name, strings.Join(missing, ", "))
+	}
+
+	err := migrateGuild(plugin, guildID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", plugin.Info.Name, err)
+	}
+
+	// The approved capabilities are stored so that the plugin can be disabled
+	// if a new version requires more than the guild's admins have seen. They're
+	// stored first, so the plugin is never enabled without an approval.
+	err = db.SetPluginApproval(plugin.Info.Name, guildID, plugin.Info.Capabilities)
+	if err != nil {
+		return err
+	}
+
+	err = enablePlugin(guildID, plugin.Info.Name)
 	if err != nil {
 		return err
 	}
 
+	err = syncGuildCommands(s, guildID)
+	if err != nil {
+		// Don't leave the plugin enabled if its commands couldn't be published
+		return errors.Join(err, disablePlugin(guildID, plugin.Info.Name))
+	}
+
 	if plugin.api.OnEnable != nil {
 		callable, ok := goja.AssertFunction(plugin.api.OnEnable)
 		if !ok {
 			return fmt.Errorf("onEnable value is not callable")
 		}
 
-		errCh := make(chan error)
-		plugin.Loop.RunOnLoop(func(vm *goja.Runtime) {
-			_, err := callable(vm.ToValue(plugin.api), vm.ToValue(i.GuildID))
-			errCh <- err
+		err = <-callOnLoop(plugin.api, func(vm *goja.Runtime) error {
+			_, err := callable(vm.ToValue(plugin.api), vm.ToValue(guildID))
+			return err
 		})
-		if err := <-errCh; err != nil {
+		if err != nil {
 			return fmt.Errorf("%s onEnable: %w", plugin.Info.Name, err)
 		}
 	}
 
-	return util.RespondEphemeral(s, i.Interaction, fmt.Sprintf("Successfully enabled the %q plugin!", pluginName))
+	return nil
 }
 
 // disableCmd handles the `/plugin disable` command.
@@ -88,7 +174,32 @@ func disableCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 		return fmt.Errorf("no such plugin: %q", pluginName)
 	}
 
-	err := disablePlugin(i.GuildID, pluginName)
+	if dependents := enabledDependents(i.GuildID, plugin); len(dependents) > 0 {
+		return fmt.Errorf("plugin %q is required by enabled plugins: %s", pluginName, strings.Join(dependents, ", "))
+	}
+
+	err := disable(s, i.GuildID, plugin)
+	if err != nil {
+		return err
+	}
+
+	return util.RespondEphemeral(s, i.Interaction, fmt.Sprintf("Successfully disabled the %q plugin", pluginName))
+}
+
+// disable disables a plugin in the given guild, removes its commands,
+// and calls its onDisable hook.
+func disable(s *discordgo.Session, guildID string, plugin *Plugin) error {
+	err := disablePlugin(guildID, plugin.Info.Name)
+	if err != nil {
+		return err
+	}
+
+	err = cancelSchedules(guildID, plugin.Info.Name)
+	if err != nil {
+		return err
+	}
+
+	err = syncGuildCommands(s, guildID)
 	if err != nil {
 		return err
 	}
@@ -99,17 +210,303 @@ func disableCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 			return fmt.Errorf("onDisable value is not callable")
 		}
 
-		errCh := make(chan error)
-		plugin.Loop.RunOnLoop(func(vm *goja.Runtime) {
-			_, err := callable(vm.ToValue(plugin.api), vm.ToValue(i.GuildID))
-			errCh <- err
+		err = <-callOnLoop(plugin.api, func(vm *goja.Runtime) error {
+			_, err := callable(vm.ToValue(plugin.api), vm.ToValue(guildID))
+			return err
 		})
-		if err := <-errCh; err != nil {
+		if err != nil {
 			return fmt.Errorf("%s onDisable: %w", plugin.Info.Name, err)
 		}
 	}
 
-	return util.RespondEphemeral(s, i.Interaction, fmt.Sprintf("Successfully disabled the %q plugin", pluginName))
+	return nil
+}
+
+// reloadCmd handles the `/pluginadm reload` command.
+func reloadCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	data := i.ApplicationCommandData()
+	args := data.Options[0].Options
+
+	// Reloading affects every guild the plugins are enabled in,
+	// so only the bot's owner is allowed to do it.
+	owner, err := isBotOwner(s, i.Member.User.ID)
+	if err != nil {
+		return err
+	} else if !owner {
+		return errors.New("only the bot's owner can reload plugins")
+	}
+
+	toReload := allPlugins()
+	if len(args) > 0 {
+		pluginName := args[0].StringValue()
+		plugin, ok := findPlugin(pluginName)
+		if !ok {
+			return fmt.Errorf("no such plugin: %q", pluginName)
+		}
+		toReload = []*Plugin{plugin}
+	}
+
+	// Reloading plugins might take longer than Discord
+	// allows for a response, so the response is deferred.
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		return err
+	}
+
+	sb := strings.Builder{}
+	for _, plugin := range toReload {
+		newPlugin, err := reload(plugin, s)
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("Error reloading %q: %s\n", plugin.Info.Name, err))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("Reloaded %s (%s)\n", newPlugin.Info.Name, newPlugin.Info.Version))
+	}
+
+	msg := sb.String()
+	if msg == "" {
+		msg = "There are no plugins to reload"
+	}
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg})
+	return err
+}
+
+// configCmd handles the `/pluginadm config` command.
+func configCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	var (
+		pluginName, settingName, value string
+		hasValue, reset                bool
+	)
+	for _, opt := range i.ApplicationCommandData().Options[0].Options {
+		switch opt.Name {
+		case "plugin":
+			pluginName = opt.StringValue()
+		case "setting":
+			settingName = opt.StringValue()
+		case "value":
+			value, hasValue = opt.StringValue(), true
+		case "reset":
+			reset = opt.BoolValue()
+		}
+	}
+
+	plugin, ok := findPlugin(pluginName)
+	if !ok {
+		return fmt.Errorf("no such plugin: %q", pluginName)
+	}
+
+	if !pluginEnabled(i.GuildID, pluginName) {
+		return fmt.Errorf("plugin %q is not enabled in this guild", pluginName)
+	}
+
+	if settingName == "" {
+		return showConfig(s, i, plugin)
+	}
+
+	setting, ok := findSetting(plugin.Info, settingName)
+	if !ok {
+		return fmt.Errorf("plugin %q has no setting named %q", pluginName, settingName)
+	}
+
+	switch {
+	case reset && hasValue:
+		return errors.New("a setting can't be reset and given a value at the same time")
+	case reset:
+		err := setConfig(i.GuildID, plugin, setting, nil)
+		if err != nil {
+			return err
+		}
+		return util.RespondEphemeral(s, i.Interaction, fmt.Sprintf("Successfully reset `%s` to its default value!", setting.Name))
+	case !hasValue:
+		config, err := guildConfig(plugin.Info, i.GuildID)
+		if err != nil {
+			return err
+		}
+		return util.RespondEphemeral(s, i.Interaction, fmt.Sprintf("`%s` is currently set to %s.", setting.Name, formatSetting(setting, config[setting.Name])))
+	}
+
+	v, err := parseSetting(s, i.GuildID, setting, value)
+	if err != nil {
+		return err
+	}
+
+	err = setConfig(i.GuildID, plugin, setting, v)
+	if err != nil {
+		return err
+	}
+
+	return util.RespondEphemeral(s, i.Interaction, fmt.Sprintf("Successfully set `%s` to %s!", setting.Name, formatSetting(setting, v)))
+}
+
+// showConfig responds with the current values of all of a plugin's settings
+func showConfig(s *discordgo.Session, i *discordgo.InteractionCreate, plugin *Plugin) error {
+	if len(plugin.Info.Settings) == 0 {
+		return util.RespondEphemeral(s, i.Interaction, fmt.Sprintf("The %q plugin doesn't have any settings.", plugin.Info.Name))
+	}
+
+	config, err := guildConfig(plugin.Info, i.GuildID)
+	if err != nil {
+		return err
+	}
+
+	embed := &discordgo.MessageEmbed{Title: plugin.Info.Name + " settings"}
+	for _, setting := range plugin.Info.Settings {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("%s (%s)", setting.Name, setting.Type),
+			Value: setting.Desc + "\nCurrent value: " + formatSetting(setting, config[setting.Name]),
+		})
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:  discordgo.MessageFlagsEphemeral,
+			Embeds: []*discordgo.MessageEmbed{embed},
+		},
+	})
+}
+
+// statusCmd handles the `/pluginadm status` command.
+func statusCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	args := i.ApplicationCommandData().Options[0].Options
+	if len(args) > 0 {
+		pluginName := args[0].StringValue()
+		plugin, ok := findPlugin(pluginName)
+		if !ok {
+			return fmt.Errorf("no such plugin: %q", pluginName)
+		}
+		return showStatus(s, i, plugin)
+	}
+
+	embed := &discordgo.MessageEmbed{Title: "Plugin status"}
+	for _, plugin := range allPlugins() {
+		if !pluginEnabled(i.GuildID, plugin.Info.Name) {
+			continue
+		}
+
+		// Discord doesn't allow more than 25 fields in an embed
+		if len(embed.Fields) == 25 {
+			embed.Footer = &discordgo.MessageEmbedFooter{Text: "Some plugins were left out. Use the plugin option to view them."}
+			break
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("%s (%s)", plugin.Info.Name, plugin.Info.Version),
+			Value: formatHealth(healthStats(plugin.Info.Name, i.GuildID)),
+		})
+	}
+
+	if len(embed.Fields) == 0 {
+		return util.RespondEphemeral(s, i.Interaction, "There are no plugins enabled in this guild.")
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:  discordgo.MessageFlagsEphemeral,
+			Embeds: []*discordgo.MessageEmbed{embed},
+		},
+	})
+}
+
+// showStatus responds with a plugin's health statistics and its most recent errors
+func showStatus(s *discordgo.Session, i *discordgo.InteractionCreate, plugin *Plugin) error {
+	ph := healthStats(plugin.Info.Name, i.GuildID)
+
+	embed := &discordgo.MessageEmbed{
+		Title:       plugin.Info.Name + " status",
+		Description: formatHealth(ph),
+	}
+
+	// Show the newest errors first
+	for j := len(ph.Recent) - 1; j >= 0; j-- {
+		pe := ph.Recent[j]
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  truncate(pe.Source, 256),
+			Value: fmt.Sprintf("<t:%d:R>\n%s", pe.Time.Unix(), formatPluginError(pe)),
+		})
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:  discordgo.MessageFlagsEphemeral,
+			Embeds: []*discordgo.MessageEmbed{embed},
+		},
+	})
+}
+
+// formatHealth formats a plugin's health statistics for display in Discord
+func formatHealth(ph pluginHealth) string {
+	if ph.Invocations == 0 {
+		return "No handlers have run since owobot started."
+	}
+
+	out := fmt.Sprintf(
+		"Invocations: %d\nErrors: %d\nAverage latency: %s\nMax latency: %s",
+		ph.Invocations,
+		ph.Errors,
+		ph.AvgLatency().Round(time.Microsecond),
+		ph.MaxLatency.Round(time.Microsecond),
+	)
+
+	if len(ph.Recent) > 0 {
+		out += fmt.Sprintf("\nLast error: <t:%d:R>", ph.Recent[len(ph.Recent)-1].Time.Unix())
+	}
+
+	return out
+}
+
+// errorLogCmd handles the `/pluginadm errorlog` command.
+func errorLogCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	enabled := i.ApplicationCommandData().Options[0].Options[0].BoolValue()
+
+	err := db.SetPluginErrorLog(i.GuildID, enabled)
+	if err != nil {
+		return err
+	}
+
+	if enabled {
+		return util.RespondEphemeral(s, i.Interaction, "Plugin errors will now be sent to the event log channel.")
+	}
+	return util.RespondEphemeral(s, i.Interaction, "Plugin errors will no longer be sent to the event log channel.")
+}
+
+// installCmd handles the `/pluginadm install` command. Since it affects every
+// guild, only the bot's owner can use it.
+func installCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	name := i.ApplicationCommandData().Options[0].Options[0].StringValue()
+
+	owner, err := isBotOwner(s, i.Member.User.ID)
+	if err != nil {
+		return err
+	} else if !owner {
+		return errors.New("only the bot's owner can install plugins")
+	}
+
+	// Downloading the plugin might take longer than Discord
+	// allows for a response, so the response is deferred.
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		return err
+	}
+
+	msg := ""
+	plugin, err := installPlugin(s, name)
+	if err != nil {
+		msg = fmt.Sprintf("Error installing %q: %s", name, err)
+	} else {
+		msg = fmt.Sprintf("Installed %s (%s)", plugin.Info.Name, plugin.Info.Version)
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg})
+	return err
 }
 
 func pluginCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
@@ -134,7 +531,7 @@ func pluginHelpCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 		return err
 	}
 
-	for _, plugin := range Plugins {
+	for _, plugin := range allPlugins() {
 		if !pluginEnabled(i.GuildID, plugin.Info.Name) {
 			continue
 		}
@@ -203,7 +600,7 @@ func pluginRunCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 		return err
 	}
 
-	for _, plugin := range Plugins {
+	for _, plugin := range allPlugins() {
 		if !pluginEnabled(i.GuildID, plugin.Info.Name) {
 			continue
 		}
@@ -224,29 +621,27 @@ func pluginRunCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 			return fmt.Errorf("value in onExec is not callable")
 		}
 
-		errCh := make(chan error)
-		plugin.Loop.RunOnLoop(func(vm *goja.Runtime) {
-			_, err = callable(
+		return <-invoke(plugin.api, i.GuildID, "command "+cmd.Name, func(vm *goja.Runtime) error {
+			_, err := callable(
 				vm.ToValue(cmd),
-				vm.ToValue(s),
+				vm.ToValue(plugin.api.session(s)),
 				vm.ToValue(i),
 				vm.ToValue(newArgs),
 			)
-			errCh <- err
+			return err
 		})
-		return <-errCh
 	}
 
 	return fmt.Errorf("command not found: %q", args[0])
 }
 
-func findPlugin(name string) (Plugin, bool) {
-	for _, plugin := range Plugins {
+func findPlugin(name string) (*Plugin, bool) {
+	for _, plugin := range allPlugins() {
 		if plugin.Info.Name == name {
 			return plugin, true
 		}
 	}
-	return Plugin{}, false
+	return nil, false
 }
 
 func findCmd(cmds []Command, args []string) (Command, []string, bool) {
@@ -0,0 +1,249 @@
+package plugins
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dop251/goja"
+	"go.elara.ws/logger/log"
+)
+
+// customIDPrefix is added to the custom IDs of all plugin components and modals,
+// so that they can never conflict with the ones used by core systems.
+const customIDPrefix = "plugin:"
+
+// interactionHandler represents a plugin handler for component or modal interactions
+type interactionHandler struct {
+	prefix string
+	modal  bool
+	fn     goja.Callable
+}
+
+// maxCustomIDLength is the maximum length of a custom ID allowed by discord
+const maxCustomIDLength = 100
+
+// namespacedID namespaces a plugin-local custom ID so it can be routed back to the plugin.
+// Discord refuses custom IDs that are too long once namespaced, so those cause an error.
+func (oa *owobotAPI) namespacedID(id string) (string, error) {
+	out := customIDPrefix + oa.PluginInfo.Name + ":" + id
+	if len(out) > maxCustomIDLength {
+		return "", fmt.Errorf(
+			"custom ID %q is too long, it may be at most %d characters for this plugin",
+			id, maxCustomIDLength-len(out)+len(id),
+		)
+	}
+	return out, nil
+}
+
+// parseCustomID splits a namespaced custom ID into the name of the plugin
+// it belongs to and the plugin-local custom ID. Plugin names can't contain
+// colons, so the first one after the prefix always ends the name.
+func parseCustomID(customID string) (pluginName, id string, ok bool) {
+	rest, ok := strings.CutPrefix(customID, customIDPrefix)
+	if !ok {
+		return "", "", false
+	}
+	return strings.Cut(rest, ":")
+}
+
+// OnComponent adds a handler for message components, such as buttons and select menus,
+// whose plugin-local custom IDs start with the given prefix.
+func (oa *owobotAPI) OnComponent(prefix string, fn goja.Value) {
+	oa.addInteractionHandler(prefix, false, fn)
+}
+
+// OnModal adds a handler for submissions of modals whose plugin-local
+// custom IDs start with the given prefix.
+func (oa *owobotAPI) OnModal(prefix string, fn goja.Value) {
+	oa.addInteractionHandler(prefix, true, fn)
+}
+
+func (oa *owobotAPI) addInteractionHandler(prefix string, modal bool, fn goja.Value) {
+	if !oa.PluginInfo.IsValid() {
+		log.Warn("No plugin information provided, ignoring handler registration.").Str("path", oa.path).Send()
+		return
+	}
+
+	callable, ok := goja.AssertFunction(fn)
+	if !ok {
+		log.Warn("Value passed to interaction handler registrar is not a function, ignoring.").
+			Str("plugin", oa.PluginInfo.Name).
+			Str("prefix", prefix).
+			Send()
+		return
+	}
+
+	oa.mu.Lock()
+	defer oa.mu.Unlock()
+
+	for _, h := range oa.interactionHandlers {
+		if h.prefix == prefix && h.modal == modal {
+			log.Warn("Interaction handler prefix already registered, ignoring.").
+				Str("plugin", oa.PluginInfo.Name).
+				Str("prefix", prefix).
+				Send()
+			return
+		}
+	}
+
+	oa.interactionHandlers = append(oa.interactionHandlers, interactionHandler{
+		prefix: prefix,
+		modal:  modal,
+		fn:     callable,
+	})
+}
+
+// findInteractionHandler finds the handler with the longest prefix matching id
+func (oa *owobotAPI) findInteractionHandler(id string, modal bool) (interactionHandler, bool) {
+	oa.mu.Lock()
+	defer oa.mu.Unlock()
+
+	var (
+		out   interactionHandler
+		found bool
+	)
+	for _, h := range oa.interactionHandlers {
+		if h.modal != modal || !strings.HasPrefix(id, h.prefix) {
+			continue
+		}
+		if !found || len(h.prefix) > len(out.prefix) {
+			out, found = h, true
+		}
+	}
+	return out, found
+}
+
+// Button creates a new button. Its custom ID is namespaced to this plugin,
+// unless it's a link button, which doesn't have one.
+func (oa *owobotAPI) Button(b discordgo.Button) (discordgo.Button, error) {
+	if b.Style != discordgo.LinkButton {
+		id, err := oa.namespacedID(b.CustomID)
+		if err != nil {
+			return discordgo.Button{}, err
+		}
+		b.CustomID = id
+	}
+	if b.Style == 0 {
+		b.Style = discordgo.PrimaryButton
+	}
+	return b, nil
+}
+
+// SelectMenu creates a new select menu with its custom ID namespaced to this plugin
+func (oa *owobotAPI) SelectMenu(sm discordgo.SelectMenu) (discordgo.SelectMenu, error) {
+	id, err := oa.namespacedID(sm.CustomID)
+	if err != nil {
+		return discordgo.SelectMenu{}, err
+	}
+	sm.CustomID = id
+	return sm, nil
+}
+
+// TextInput creates a new text input for use in modals
+func (oa *owobotAPI) TextInput(ti discordgo.TextInput) discordgo.TextInput {
+	if ti.Style == 0 {
+		ti.Style = discordgo.TextInputShort
+	}
+	return ti
+}
+
+// ActionsRow creates a new actions row containing the given components
+func (oa *owobotAPI) ActionsRow(components ...discordgo.MessageComponent) discordgo.ActionsRow {
+	return discordgo.ActionsRow{Components: components}
+}
+
+// Modal creates an interaction response that opens a modal with the
+// given title and components. Its custom ID is namespaced to this plugin.
+func (oa *owobotAPI) Modal(customID, title string, components ...discordgo.MessageComponent) (*discordgo.InteractionResponse, error) {
+	id, err := oa.namespacedID(customID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, component := range components {
+		// Modals require text inputs to be wrapped in actions rows,
+		// so we do that automatically if the plugin didn't.
+		if ti, ok := component.(discordgo.TextInput); ok {
+			components[i] = discordgo.ActionsRow{Components: []discordgo.MessageComponent{ti}}
+		}
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID:   id,
+			Title:      title,
+			Components: components,
+		},
+	}, nil
+}
+
+// handleComponent routes component and modal interactions with namespaced
+// custom IDs to the plugin they belong to.
+func handleComponent(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	var (
+		customID string
+		modal    bool
+	)
+
+	switch i.Type {
+	case discordgo.InteractionMessageComponent:
+		customID = i.MessageComponentData().CustomID
+	case discordgo.InteractionModalSubmit:
+		customID = i.ModalSubmitData().CustomID
+		modal = true
+	default:
+		return nil
+	}
+
+	pluginName, id, ok := parseCustomID(customID)
+	if !ok {
+		return nil
+	}
+
+	if !pluginEnabled(i.GuildID, pluginName) {
+		return fmt.Errorf("plugin %q is not enabled in this guild", pluginName)
+	}
+
+	plugin, ok := findPlugin(pluginName)
+	if !ok {
+		return fmt.Errorf("no such plugin: %q", pluginName)
+	}
+
+	h, ok := plugin.api.findInteractionHandler(id, modal)
+	if !ok {
+		return fmt.Errorf("%s: no handler for %q", pluginName, id)
+	}
+
+	var data any
+	if modal {
+		data = modalValues(i.ModalSubmitData().Components)
+	} else {
+		mcd := i.MessageComponentData()
+		mcd.CustomID = id
+		data = mcd
+	}
+
+	return <-invoke(plugin.api, i.GuildID, "interaction "+id, func(vm *goja.Runtime) error {
+		_, err := h.fn(vm.ToValue(plugin.api), vm.ToValue(plugin.api.session(s)), vm.ToValue(i), vm.ToValue(id), vm.ToValue(data))
+		return err
+	})
+}
+
+// modalValues returns a map of the custom IDs of all the text inputs in
+// a submitted modal to their values.
+func modalValues(components []discordgo.MessageComponent) map[string]string {
+	out := map[string]string{}
+	for _, component := range components {
+		switch component := component.(type) {
+		case *discordgo.ActionsRow:
+			for k, v := range modalValues(component.Components) {
+				out[k] = v
+			}
+		case *discordgo.TextInput:
+			out[component.CustomID] = component.Value
+		}
+	}
+	return out
+}
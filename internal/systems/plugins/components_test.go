@@ -0,0 +1,177 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dop251/goja"
+	"go.elara.ws/owobot/internal/db"
+)
+
+func TestParseCustomID(t *testing.T) {
+	cases := []struct {
+		customID   string
+		pluginName string
+		id         string
+		ok         bool
+	}{
+		{"plugin:polls:vote:up", "polls", "vote:up", true},
+		{"plugin:polls:", "polls", "", true},
+		{"plugin:polls", "polls", "", false},
+		{"pluginadm-enable:polls", "", "", false},
+		{"polls:vote", "", "", false},
+	}
+
+	for _, c := range cases {
+		pluginName, id, ok := parseCustomID(c.customID)
+		if pluginName != c.pluginName || id != c.id || ok != c.ok {
+			t.Errorf("parseCustomID(%q) = %q, %q, %t, expected %q, %q, %t", c.customID, pluginName, id, ok, c.pluginName, c.id, c.ok)
+		}
+	}
+}
+
+func TestFindInteractionHandler(t *testing.T) {
+	oa := &owobotAPI{interactionHandlers: []interactionHandler{
+		{prefix: "vote"},
+		{prefix: "vote:up"},
+		{prefix: "vote:", modal: true},
+		{prefix: ""},
+	}}
+
+	cases := []struct {
+		id     string
+		modal  bool
+		prefix string
+		ok     bool
+	}{
+		{"vote:up:1", false, "vote:up", true},
+		{"vote:down:1", false, "vote", true},
+		{"other", false, "", true},
+		{"vote:up", true, "vote:", true},
+		{"other", true, "", false},
+	}
+
+	for _, c := range cases {
+		h, ok := oa.findInteractionHandler(c.id, c.modal)
+		if ok != c.ok || (ok && h.prefix != c.prefix) {
+			t.Errorf("findInteractionHandler(%q, %t) = %q, %t, expected %q, %t", c.id, c.modal, h.prefix, ok, c.prefix, c.ok)
+		}
+	}
+}
+
+func TestCustomIDLength(t *testing.T) {
+	oa := &owobotAPI{PluginInfo: db.PluginInfo{Name: "polls"}}
+	// "plugin:polls:" is 13 characters long
+	maxID := strings.Repeat("a", maxCustomIDLength-13)
+
+	b, err := oa.Button(discordgo.Button{CustomID: maxID})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(b.CustomID) != maxCustomIDLength {
+		t.Errorf("expected a %d character custom ID, got %q", maxCustomIDLength, b.CustomID)
+	}
+
+	if _, err := oa.Button(discordgo.Button{CustomID: maxID + "a"}); err == nil {
+		t.Error("button with a custom ID that's too long was created")
+	}
+	if _, err := oa.SelectMenu(discordgo.SelectMenu{CustomID: maxID + "a"}); err == nil {
+		t.Error("select menu with a custom ID that's too long was created")
+	}
+	if _, err := oa.Modal(maxID+"a", "title"); err == nil {
+		t.Error("modal with a custom ID that's too long was created")
+	}
+
+	// Link buttons don't have custom IDs
+	if _, err := oa.Button(discordgo.Button{Style: discordgo.LinkButton, URL: "https://example.com"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandleComponent(t *testing.T) {
+	err := db.Init(context.Background(), "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	dir := t.TempDir()
+	writePlugin := func(file, src string) {
+		err := os.WriteFile(filepath.Join(dir, file), []byte(src), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	writePlugin("buttons.js", `
+owobot.pluginInfo = {name: "buttons", version: "1", desc: "d"}
+var clicked = []
+owobot.onComponent("vote", function(s, i, id) { clicked.push("vote " + id) })
+owobot.onComponent("vote:up", function(s, i, id) { clicked.push("up " + id) })
+`)
+	writePlugin("colon.js", `owobot.pluginInfo = {name: "bad:name", version: "1", desc: "d"}`)
+
+	err = Load(dir, Config{}, &discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Start(&discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, plugin := range allPlugins() {
+			unload(plugin)
+		}
+	})
+
+	if _, ok := findPlugin("bad:name"); ok {
+		t.Error("plugin with a colon in its name was loaded")
+	}
+
+	plugin, ok := findPlugin("buttons")
+	if !ok {
+		t.Fatal("plugin wasn't loaded")
+	}
+
+	click := func(customID string) error {
+		return handleComponent(&discordgo.Session{}, &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+			Type:    discordgo.InteractionMessageComponent,
+			GuildID: "1",
+			Data:    discordgo.MessageComponentInteractionData{CustomID: customID},
+		}})
+	}
+
+	if err := click("plugin:buttons:vote:up:1"); err == nil {
+		t.Error("component of a plugin that isn't enabled was handled")
+	}
+
+	err = db.CreateGuild("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = enablePlugin("1", "buttons")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, customID := range []string{"plugin:buttons:vote:up:1", "plugin:buttons:vote:down:1"} {
+		if err := click(customID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var clicked []string
+	err = <-callOnLoop(plugin.api, func(vm *goja.Runtime) error {
+		return vm.ExportTo(vm.Get("clicked"), &clicked)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(clicked, ",") != "up vote:up:1,vote vote:down:1" {
+		t.Errorf("expected each click to reach the handler with the longest matching prefix, got %q", clicked)
+	}
+}
@@ -0,0 +1,98 @@
+package plugins
+
+import (
+	"time"
+
+	"go.elara.ws/owobot/internal/util"
+)
+
+// Config contains the configuration for the plugin system
+type Config struct {
+	// CallTimeout is the maximum amount of time a single call into
+	// a plugin, such as an event handler or command, may run for
+	// before it's interrupted. A zero value disables the deadline.
+	CallTimeout util.Duration `env:"CALL_TIMEOUT" toml:"call_timeout"`
+
+	// WatchInterval is how often the plugin directory is checked for
+	// changes so plugins can be reloaded. A zero value disables the watcher.
+	WatchInterval util.Duration `env:"WATCH_INTERVAL" toml:"watch_interval"`
+
+	// LibDir is a directory containing shared modules that every
+	// plugin may require. An empty value disables it.
+	LibDir string `env:"LIB_DIR" toml:"lib_dir"`
+
+	// FetchTimeout is the maximum amount of time a fetch request made by
+	// a plugin may take, including reading the body. A zero value disables it.
+	FetchTimeout util.Duration `env:"FETCH_TIMEOUT" toml:"fetch_timeout"`
+
+	// FetchMaxBodySize is the maximum size in bytes of a response body
+	// that plugins may fetch. A zero value disables the limit.
+	FetchMaxBodySize int64 `env:"FETCH_MAX_BODY_SIZE" toml:"fetch_max_body_size"`
+
+	// FetchMaxCookies is the maximum amount of cookies each plugin's
+	// cookie jar may store. A zero value disables the limit.
+	FetchMaxCookies int `env:"FETCH_MAX_COOKIES" toml:"fetch_max_cookies"`
+
+	// FetchAllowlist maps plugin names to the hosts they may make requests to.
+	// Plugins listed here are restricted to these hosts, even if they declare
+	// access to others. Plugins that aren't listed are only restricted by their
+	// declared capabilities. It can only be set in the config file.
+	FetchAllowlist map[string][]string `toml:"fetch_allowlist"`
+
+	// SQLMaxRows is the maximum amount of rows a single plugin SQL
+	// query may return. A zero value disables the limit.
+	SQLMaxRows int `env:"SQL_MAX_ROWS" toml:"sql_max_rows"`
+
+	// MaxViolations is the amount of times a plugin may exceed its limits
+	// within ViolationWindow before it's automatically disabled in every
+	// guild. A zero value disables automatic disabling.
+	MaxViolations int `env:"MAX_VIOLATIONS" toml:"max_violations"`
+
+	// ViolationWindow is the period of time within which limit violations
+	// are counted towards MaxViolations.
+	ViolationWindow util.Duration `env:"VIOLATION_WINDOW" toml:"violation_window"`
+
+	// PrivilegedIntents contains the names of the privileged gateway intents
+	// that are enabled for the bot in the Discord developer portal. Plugins
+	// that require other privileged intents won't receive their events.
+	PrivilegedIntents []string `env:"PRIVILEGED_INTENTS" toml:"privileged_intents"`
+
+	// LogLevel is the minimum level of the messages plugins may log,
+	// which can be different from the level of owobot's own logs.
+	LogLevel string `env:"LOG_LEVEL" toml:"log_level"`
+
+	// LogLevels maps plugin names to log levels that override LogLevel
+	// for those plugins. It can only be set in the config file.
+	LogLevels map[string]string `toml:"log_levels"`
+
+	// RegistryURL is the URL of the index of the plugin registry that
+	// plugins can be installed from. It may be an HTTP(S) or file URL.
+	// An empty value disables installing plugins.
+	RegistryURL string `env:"REGISTRY_URL" toml:"registry_url"`
+
+	// RegistryKeys contains the base64-encoded ed25519 public keys that
+	// are trusted to sign plugins in the registry. Plugins without a valid
+	// signature from one of these keys can't be installed.
+	RegistryKeys []string `env:"REGISTRY_KEYS" toml:"registry_keys"`
+}
+
+// DefaultConfig contains the default values for the plugin configuration
+var DefaultConfig = Config{
+	CallTimeout:      util.Duration(5 * time.Second),
+	WatchInterval:    util.Duration(5 * time.Second),
+	FetchTimeout:     util.Duration(10 * time.Second),
+	FetchMaxBodySize: 10 << 20,
+	FetchMaxCookies:  100,
+	SQLMaxRows:       1000,
+	MaxViolations:    5,
+	ViolationWindow:  util.Duration(10 * time.Minute),
+	// owobot itself always requires these intents, so they have to be enabled
+	PrivilegedIntents: []string{"GuildMembers", "MessageContent"},
+	LogLevel:          "info",
+}
+
+// cfg is the active plugin configuration, set by [Load]
+var cfg = DefaultConfig
+
+// pluginDir is the directory plugins are loaded from, set by [Load]
+var pluginDir string
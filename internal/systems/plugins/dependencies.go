@@ -0,0 +1,102 @@
+package plugins
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// sortByDependencies sorts plugins so that every plugin comes after all of
+// its dependencies. Plugins whose dependencies are missing, that are part of a
+// dependency cycle, or that depend on such plugins can't be sorted. They're left
+// out of the sorted plugins and returned along with the reason instead.
+func sortByDependencies(plugins []*Plugin) ([]*Plugin, map[*Plugin]error) {
+	byName := make(map[string]*Plugin, len(plugins))
+	for _, plugin := range plugins {
+		byName[plugin.Info.Name] = plugin
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+		failed
+	)
+
+	state := map[string]int{}
+	out := make([]*Plugin, 0, len(plugins))
+	failures := map[*Plugin]error{}
+
+	var visit func(plugin *Plugin, chain []string) error
+	visit = func(plugin *Plugin, chain []string) (err error) {
+		name := plugin.Info.Name
+		chain = append(chain, name)
+
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(chain, " -> "))
+		case visited:
+			return nil
+		case failed:
+			return failures[plugin]
+		}
+
+		state[name] = visiting
+		defer func() {
+			if err != nil {
+				state[name] = failed
+				failures[plugin] = err
+			}
+		}()
+
+		for _, dep := range plugin.Info.Dependencies {
+			depPlugin, ok := byName[dep]
+			if !ok {
+				// The dependency may have been loaded earlier
+				if _, ok := findPlugin(dep); ok {
+					continue
+				}
+				return fmt.Errorf("%s: dependency %q is not loaded", name, dep)
+			}
+
+			err := visit(depPlugin, chain)
+			if err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+
+		out = append(out, plugin)
+		return nil
+	}
+
+	for _, plugin := range plugins {
+		visit(plugin, nil)
+	}
+
+	return out, failures
+}
+
+// missingDependencies returns the dependencies of the plugin
+// that aren't enabled in the given guild.
+func missingDependencies(guildID string, plugin *Plugin) []string {
+	var out []string
+	for _, dep := range plugin.Info.Dependencies {
+		if !pluginEnabled(guildID, dep) {
+			out = append(out, dep)
+		}
+	}
+	return out
+}
+
+// enabledDependents returns the names of the plugins enabled
+// in the given guild that depend on the plugin.
+func enabledDependents(guildID string, plugin *Plugin) []string {
+	var out []string
+	for _, p := range allPlugins() {
+		if slices.Contains(p.Info.Dependencies, plugin.Info.Name) && pluginEnabled(guildID, p.Info.Name) {
+			out = append(out, p.Info.Name)
+		}
+	}
+	return out
+}
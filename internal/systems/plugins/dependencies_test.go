@@ -0,0 +1,50 @@
+package plugins
+
+import (
+	"slices"
+	"testing"
+
+	"go.elara.ws/owobot/internal/db"
+)
+
+func TestSortByDependencies(t *testing.T) {
+	newPlugin := func(name string, deps ...string) *Plugin {
+		return &Plugin{Info: db.PluginInfo{Name: name, Dependencies: deps}}
+	}
+
+	plugins := []*Plugin{
+		newPlugin("f", "a"),
+		newPlugin("a"),
+		newPlugin("b", "missing"),
+		newPlugin("c", "b"),
+		newPlugin("d", "e"),
+		newPlugin("e", "d"),
+		newPlugin("g", "f", "a"),
+	}
+
+	ordered, failed := sortByDependencies(plugins)
+
+	var names []string
+	for _, plugin := range ordered {
+		names = append(names, plugin.Info.Name)
+	}
+	if expected := []string{"a", "f", "g"}; !slices.Equal(names, expected) {
+		t.Errorf("expected %v to be sorted, got %v", expected, names)
+	}
+
+	failedNames := map[string]bool{}
+	for plugin, err := range failed {
+		if err == nil {
+			t.Errorf("plugin %s failed without an error", plugin.Info.Name)
+		}
+		failedNames[plugin.Info.Name] = true
+	}
+	for _, name := range []string{"b", "c", "d", "e"} {
+		if !failedNames[name] {
+			t.Errorf("expected plugin %s to fail", name)
+		}
+	}
+	if len(failedNames) != 4 {
+		t.Errorf("expected 4 plugins to fail, got %v", failedNames)
+	}
+}
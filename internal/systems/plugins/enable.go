@@ -3,13 +3,26 @@ package plugins
 import (
 	"fmt"
 	"slices"
+	"sync"
+	"time"
 
+	"go.elara.ws/logger/log"
 	"go.elara.ws/owobot/internal/db"
 )
 
-var enabled = map[string][]string{}
+// enabledRefreshInterval is how often the enabled plugins are reloaded from the database
+const enabledRefreshInterval = time.Minute
+
+var (
+	enabledMtx = sync.RWMutex{}
+	enabled    = map[string][]string{}
+)
 
 func loadEnabled() error {
+	// The lock is held while the database is read, so that a plugin
+	// enabled or disabled in the meantime isn't overwritten.
+	enabledMtx.Lock()
+	defer enabledMtx.Unlock()
 	guilds, err := db.AllGuilds()
 	if err != nil {
 		return err
@@ -20,7 +33,23 @@ func loadEnabled() error {
 	return nil
 }
 
+// refreshEnabled periodically reloads the enabled plugins from the database.
+// When shards are split across processes, a plugin may be enabled or disabled
+// by a process other than the one that runs the guild's shard, such as when an
+// HTTP interaction reaches it. The cache of every other process is stale until
+// the next refresh.
+func refreshEnabled() {
+	for range time.Tick(enabledRefreshInterval) {
+		err := loadEnabled()
+		if err != nil {
+			log.Warn("Error reloading enabled plugins").Err(err).Send()
+		}
+	}
+}
+
 func enablePlugin(guildID, pluginName string) error {
+	enabledMtx.Lock()
+	defer enabledMtx.Unlock()
 	if slices.Contains(enabled[guildID], pluginName) {
 		return fmt.Errorf("plugin %q is already enabled", pluginName)
 	}
@@ -29,6 +58,8 @@ func enablePlugin(guildID, pluginName string) error {
 }
 
 func disablePlugin(guildID, pluginName string) error {
+	enabledMtx.Lock()
+	defer enabledMtx.Unlock()
 	if i := slices.Index(enabled[guildID], pluginName); i > -1 {
 		enabled[guildID] = append(enabled[guildID][:i], enabled[guildID][i+1:]...)
 	} else {
@@ -41,5 +72,29 @@ func pluginEnabled(guildID, pluginName string) bool {
 	if guildID == "" {
 		return false
 	}
+	enabledMtx.RLock()
+	defer enabledMtx.RUnlock()
 	return slices.Contains(enabled[guildID], pluginName)
 }
+
+// guildsWithPlugins returns the IDs of all the guilds that have at least
+// one plugin enabled. If any plugin names are provided, only guilds that
+// have at least one of those plugins enabled are returned.
+func guildsWithPlugins(pluginNames ...string) []string {
+	enabledMtx.RLock()
+	defer enabledMtx.RUnlock()
+
+	var out []string
+	for guildID, guildPlugins := range enabled {
+		if len(guildPlugins) == 0 {
+			continue
+		}
+
+		if len(pluginNames) == 0 || slices.ContainsFunc(pluginNames, func(name string) bool {
+			return slices.Contains(guildPlugins, name)
+		}) {
+			out = append(out, guildID)
+		}
+	}
+	return out
+}
@@ -0,0 +1,163 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var (
+	messageEndpoint  = regexp.MustCompile(`^/channels/(\d+)/messages$`)
+	callbackEndpoint = regexp.MustCompile(`^/interactions/[^/]+/[^/]+/callback$`)
+)
+
+// fakeDiscord is an HTTP transport that pretends to be the discord API.
+// It records the messages and interaction responses sent through it.
+type fakeDiscord struct {
+	routes map[string]json.RawMessage
+
+	mu        sync.Mutex
+	nextID    int
+	messages  []any
+	responses []any
+}
+
+func newFakeDiscord(routes map[string]json.RawMessage) *fakeDiscord {
+	return &fakeDiscord{routes: routes, nextID: 1000}
+}
+
+// session returns a discord session that sends all its requests to fd
+func (fd *fakeDiscord) session() *discordgo.Session {
+	// discordgo.New only returns an error if it's given invalid arguments
+	s, _ := discordgo.New("Bot test")
+	s.Client = &http.Client{Transport: fd}
+	s.State.Application = &discordgo.Application{ID: "1"}
+	s.State.User = &discordgo.User{ID: "1", Username: "owobot", Bot: true}
+	return s
+}
+
+// reset clears all the recorded messages and interaction responses
+func (fd *fakeDiscord) reset() {
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+	fd.messages = nil
+	fd.responses = nil
+}
+
+// results returns the recorded messages and interaction responses
+func (fd *fakeDiscord) results() (messages, responses []any) {
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+	// Non-nil slices are returned so that they match empty expectations
+	return append([]any{}, fd.messages...), append([]any{}, fd.responses...)
+}
+
+func (fd *fakeDiscord) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := "/" + strings.TrimLeft(strings.TrimPrefix(req.URL.Path, "/api/v"+discordgo.APIVersion), "/")
+
+	body, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+
+	if route, ok := fd.routes[req.Method+" "+path]; ok {
+		return fakeResponse(req, http.StatusOK, route), nil
+	}
+
+	switch {
+	case req.Method == http.MethodPost && callbackEndpoint.MatchString(path):
+		fd.responses = append(fd.responses, body)
+		return fakeResponse(req, http.StatusNoContent, nil), nil
+	case req.Method == http.MethodPost && messageEndpoint.MatchString(path):
+		msg, _ := body.(map[string]any)
+		if msg == nil {
+			msg = map[string]any{}
+		}
+		msg["id"] = strconv.Itoa(fd.nextID)
+		msg["channel_id"] = messageEndpoint.FindStringSubmatch(path)[1]
+		fd.nextID++
+		fd.messages = append(fd.messages, msg)
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		return fakeResponse(req, http.StatusOK, data), nil
+	case req.Method == http.MethodGet:
+		return fakeResponse(req, http.StatusOK, []byte("{}")), nil
+	default:
+		// Echo the request body back for any other requests, since discord
+		// usually responds with the object that was created or modified.
+		data, err := json.Marshal(body)
+		if err != nil || body == nil {
+			data = []byte("{}")
+		}
+		return fakeResponse(req, http.StatusOK, data), nil
+	}
+}
+
+// requestBody decodes the JSON body of a discord API request. For multipart
+// requests, which are used to upload files, the payload_json part is decoded.
+func requestBody(req *http.Request) (any, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	defer req.Body.Close()
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		mr := multipart.NewReader(bytes.NewReader(data), params["boundary"])
+		data = nil
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+
+			if part.FormName() == "payload_json" {
+				data, err = io.ReadAll(part)
+				if err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var out any
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
+
+// fakeResponse creates an HTTP response with the given status code and body
+func fakeResponse(req *http.Request, status int, body []byte) *http.Response {
+	return &http.Response{
+		Status:     strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}
+}
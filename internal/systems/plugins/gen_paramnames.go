@@ -0,0 +1,193 @@
+//go:build ignore
+
+// gen_paramnames generates paramnames.go, which contains the parameter names
+// of the methods exposed to plugins. Reflection can't see parameter names,
+// so the TypeScript declarations get them from this table.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// pkgDir is a package directory to collect parameter names from
+type pkgDir struct {
+	importPath string
+	dir        string
+	// unexported includes unexported methods, since owobot's
+	// own packages expose some of them to plugins as method values.
+	unexported bool
+}
+
+// externalPkgs contains the packages outside of owobot that declare
+// types of values plugins can access
+var externalPkgs = []string{
+	"github.com/bwmarrin/discordgo",
+	"github.com/gorilla/websocket",
+	"bufio",
+	"crypto/tls",
+	"crypto/x509",
+	"crypto/x509/pkix",
+	"encoding/asn1",
+	"math/big",
+	"math/rand",
+	"mime/multipart",
+	"net",
+	"net/http",
+	"net/url",
+	"time",
+}
+
+func main() {
+	modPath := goList("-m")
+	modDir := goList("-m", "-f", "{{.Dir}}")
+
+	var dirs []pkgDir
+	err := filepath.WalkDir(filepath.Join(modDir, "internal"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(modDir, path)
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, pkgDir{importPath: modPath + "/" + filepath.ToSlash(rel), dir: path, unexported: true})
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Values of types from these packages are exposed to plugins too,
+	// mostly through the session's HTTP client.
+	for _, pkg := range externalPkgs {
+		dirs = append(dirs, pkgDir{importPath: pkg, dir: goList("-f", "{{.Dir}}", pkg)})
+	}
+
+	names := map[string][]string{}
+	for _, pd := range dirs {
+		collect(pd, names)
+	}
+
+	keys := make([]string, 0, len(names))
+	for key := range names {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("// Code generated by gen_paramnames.go. DO NOT EDIT.\n\n")
+	buf.WriteString("package plugins\n\n")
+	buf.WriteString("// paramNames maps the methods that may be exposed to plugins to the\n")
+	buf.WriteString("// names of their parameters, keyed by import path, receiver, and name.\n")
+	buf.WriteString("var paramNames = map[string][]string{\n")
+	for _, key := range keys {
+		fmt.Fprintf(buf, "\t%q: {%s},\n", key, quoteAll(names[key]))
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = os.WriteFile("paramnames.go", src, 0o644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// collect adds the parameter names of the methods in a package to names
+func collect(pd pkgDir, names map[string][]string) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, pd.dir, func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.Name, "_test") || pkg.Name == "main" {
+			continue
+		}
+
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				// Only methods are exposed to plugins, either through
+				// the values they're called on or as method values.
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv == nil || fn.Type.Params.NumFields() == 0 || (!pd.unexported && !fn.Name.IsExported()) {
+					continue
+				}
+
+				recv, ok := receiverName(fn.Recv.List[0].Type)
+				if !ok || (!pd.unexported && !ast.IsExported(recv)) {
+					continue
+				}
+				names[pd.importPath+"."+recv+"."+fn.Name.Name] = paramList(fn.Type.Params)
+			}
+		}
+	}
+}
+
+// receiverName returns the name of a method's receiver type
+func receiverName(expr ast.Expr) (string, bool) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		// Generic receivers can't be exposed to plugins
+		return "", false
+	}
+	return ident.Name, true
+}
+
+// paramList returns the names of the parameters in a parameter list.
+// Unnamed and blank parameters get an empty name.
+func paramList(params *ast.FieldList) []string {
+	var out []string
+	for _, field := range params.List {
+		if len(field.Names) == 0 {
+			out = append(out, "")
+			continue
+		}
+		for _, name := range field.Names {
+			if name.Name == "_" {
+				out = append(out, "")
+			} else {
+				out = append(out, name.Name)
+			}
+		}
+	}
+	return out
+}
+
+func quoteAll(ss []string) string {
+	quoted := make([]string, len(ss))
+	for i, s := range ss {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// goList runs `go list` with the given arguments and returns its trimmed output
+func goList(args ...string) string {
+	out, err := exec.Command("go", append([]string{"list"}, args...)...).Output()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return strings.TrimSpace(string(out))
+}
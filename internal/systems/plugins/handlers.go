@@ -1,11 +1,16 @@
 package plugins
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 
 	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/owobot/internal/events"
 )
 
 // HandlerFunc is an event handler function.
@@ -15,6 +20,8 @@ type HandlerFunc func(session *discordgo.Session, data any)
 type Handler struct {
 	PluginName string
 	Func       HandlerFunc
+
+	owner *owobotAPI
 }
 
 var (
@@ -22,17 +29,65 @@ var (
 	handlerMap  = map[string][]Handler{}
 )
 
+// removeHandlers removes all the event handlers registered by the given API instance.
+func removeHandlers(oa *owobotAPI) {
+	handlersMtx.Lock()
+	defer handlersMtx.Unlock()
+	for eventType, handlers := range handlerMap {
+		handlerMap[eventType] = slices.DeleteFunc(handlers, func(h Handler) bool {
+			return h.owner == oa
+		})
+	}
+}
+
+// customEvent is an event emitted by a plugin. Its payload is stored as JSON
+// so that every receiving plugin can decode its own copy, since values can't
+// be shared between runtimes.
+type customEvent struct {
+	GuildID string
+	Payload []byte
+}
+
+// payload decodes a new copy of the event's payload
+func (ce customEvent) payload() (any, error) {
+	var out any
+	err := json.Unmarshal(ce.Payload, &out)
+	return out, err
+}
+
 // handlePluginEvent handles any discord event we receive and
 // routes it to the appropriate plugin handler(s).
 func handlePluginEvent(s *discordgo.Session, data any) {
-	name := reflect.TypeOf(data).Elem().Name()
-	handlers, ok := handlerMap[name]
-	if !ok {
+	// Interactions are routed by handlePluginInteraction instead,
+	// since they might not come from the gateway.
+	if _, ok := data.(*discordgo.InteractionCreate); ok {
 		return
 	}
+	dispatchEvent(s, reflect.TypeOf(data).Elem().Name(), data)
+}
+
+// handlePluginInteraction routes interactions to the plugin handler(s)
+// for the InteractionCreate event.
+func handlePluginInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	dispatchEvent(s, "InteractionCreate", i)
+}
+
+// handleCoreEvent handles owobot's own domain events and routes
+// them to the plugin handler(s) subscribed to their names.
+func handleCoreEvent(s *discordgo.Session, e events.Event) {
+	dispatchEvent(s, e.Name(), e)
+}
+
+// dispatchEvent calls the plugin handlers for the given event name with data,
+// skipping plugins that aren't loaded or aren't enabled in the guild the event
+// came from.
+func dispatchEvent(s *discordgo.Session, name string, data any) {
+	handlersMtx.Lock()
+	handlers := slices.Clone(handlerMap[name])
+	handlersMtx.Unlock()
 
 	for _, h := range handlers {
-		if !pluginEnabled(eventGuildID(data), h.PluginName) {
+		if !h.owner.active.Load() || !pluginEnabled(eventGuildID(data), h.PluginName) {
 			continue
 		}
 
@@ -52,10 +107,16 @@ func eventGuildID(event any) string {
 		return ""
 	}
 
-	if id := evt.FieldByName("GuildID"); id.IsValid() {
+	if id, ok := fieldByName(evt, "GuildID"); ok {
 		return id.String()
-	} else if guild := evt.FieldByName("Guild"); guild.IsValid() {
-		if id := guild.FieldByName("ID"); id.IsValid() {
+	} else if guild, ok := fieldByName(evt, "Guild"); ok {
+		for guild.Kind() == reflect.Pointer && !guild.IsNil() {
+			guild = guild.Elem()
+		}
+		if guild.Kind() != reflect.Struct {
+			return ""
+		}
+		if id, ok := fieldByName(guild, "ID"); ok {
 			return id.String()
 		}
 	}
@@ -63,31 +124,93 @@ func eventGuildID(event any) string {
 	return ""
 }
 
-// handleAutocomplete handles autocomplete events for the /plugin run command.
+// fieldByName is like [reflect.Value.FieldByName], but it returns false
+// instead of panicking if the field is promoted through a nil embedded pointer.
+func fieldByName(v reflect.Value, name string) (reflect.Value, bool) {
+	f, ok := v.Type().FieldByName(name)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	field, err := v.FieldByIndexErr(f.Index)
+	return field, err == nil
+}
+
+// handleAutocomplete handles autocomplete events for the /plugin run
+// and /pluginadm config commands.
 func handleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	if i.Type != discordgo.InteractionApplicationCommandAutocomplete {
 		return
 	}
 
+	var choices []*discordgo.ApplicationCommandOptionChoice
+
 	data := i.ApplicationCommandData()
-	if data.Name != "plugin" {
+	switch data.Name {
+	case "plugin":
+		cmdStr := data.Options[0].Options[0].StringValue()
+		// Once the user is typing a command's arguments, the command
+		// names aren't useful anymore, so only the argument choices are shown.
+		if data.Options[0].Name == "run" {
+			choices = getArgChoices(i.GuildID, cmdStr, i.Member)
+		}
+		if len(choices) == 0 {
+			choices = getAllChoices(i.GuildID, cmdStr, i.Member)
+		}
+	case "pluginadm":
+		if data.Options[0].Name != "config" {
+			return
+		}
+		choices = getSettingChoices(data.Options[0].Options)
+	default:
 		return
 	}
 
-	cmdStr := data.Options[0].Options[0].StringValue()
+	// Discord rejects the whole response if it has too many choices
+	if len(choices) > maxChoices {
+		choices = choices[:maxChoices]
+	}
 
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
 		Data: &discordgo.InteractionResponseData{
-			Choices: getAllChoices(i.GuildID, cmdStr, i.Member),
+			Choices: choices,
 		},
 	})
 }
 
+// getSettingChoices returns the settings of the plugin selected
+// in the /pluginadm config command that match the partial setting name.
+func getSettingChoices(opts []*discordgo.ApplicationCommandInteractionDataOption) (out []*discordgo.ApplicationCommandOptionChoice) {
+	var pluginName, partial string
+	for _, opt := range opts {
+		switch opt.Name {
+		case "plugin":
+			pluginName = opt.StringValue()
+		case "setting":
+			partial = opt.StringValue()
+		}
+	}
+
+	plugin, ok := findPlugin(pluginName)
+	if !ok {
+		return nil
+	}
+
+	for _, setting := range plugin.Info.Settings {
+		if strings.Contains(setting.Name, partial) {
+			out = append(out, &discordgo.ApplicationCommandOptionChoice{
+				Name:  fmt.Sprintf("%s (%s)", setting.Name, setting.Type),
+				Value: setting.Name,
+			})
+		}
+	}
+	return out
+}
+
 // getAllChoices gets possible command strings for each plugin and converts them
 // to Discord command options.
 func getAllChoices(guildID, partial string, member *discordgo.Member) (out []*discordgo.ApplicationCommandOptionChoice) {
-	for _, plugin := range Plugins {
+	for _, plugin := range allPlugins() {
 		if !pluginEnabled(guildID, plugin.Info.Name) {
 			continue
 		}
@@ -132,3 +255,61 @@ func getChoiceStrs(partial, prefix string, cmds []Command, member *discordgo.Mem
 
 	return out
 }
+
+// onEnableConfirm handles the buttons on the confirmation message
+// sent by the `/pluginadm enable` command.
+func onEnableConfirm(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.Type != discordgo.InteractionMessageComponent {
+		return nil
+	}
+
+	data := i.MessageComponentData()
+
+	if data.CustomID == "pluginadm-cancel" {
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    "Cancelled.",
+				Embeds:     []*discordgo.MessageEmbed{},
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+	}
+
+	// The custom ID contains a hash of the capabilities that were shown to
+	// the user, followed by the plugin's name.
+	hashAndName, ok := strings.CutPrefix(data.CustomID, "pluginadm-enable:")
+	if !ok {
+		return nil
+	}
+	hash, pluginName, _ := strings.Cut(hashAndName, ":")
+
+	if i.Member == nil || i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		return errors.New("you don't have permission to enable plugins")
+	}
+
+	plugin, ok := findPlugin(pluginName)
+	if !ok {
+		return fmt.Errorf("no such plugin: %q", pluginName)
+	}
+
+	// The plugin may have been reloaded with different capabilities since
+	// the confirmation was sent, and those haven't been approved.
+	if hash != capabilitiesHash(plugin.Info.Capabilities) {
+		return fmt.Errorf("the capabilities of the %q plugin have changed since this message was sent, please run the command again to review them", pluginName)
+	}
+
+	err := enable(s, i.GuildID, plugin)
+	if err != nil {
+		return err
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    fmt.Sprintf("Successfully enabled the %q plugin!", pluginName),
+			Embeds:     []*discordgo.MessageEmbed{},
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+}
@@ -0,0 +1,187 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dop251/goja"
+	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/events"
+	"go.elara.ws/owobot/internal/util"
+)
+
+func TestCoreEvents(t *testing.T) {
+	err := db.Init(context.Background(), "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "listener.js"), []byte(`
+owobot.pluginInfo = {name: "listener", version: "1", desc: "d"}
+var votes = []
+owobot.on("pollVoted", function(s, e) { votes.push(e.guildID + ":" + e.option) })
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Load(dir, Config{}, &discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Start(&discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, plugin := range allPlugins() {
+			unload(plugin)
+		}
+	})
+
+	plugin, ok := findPlugin("listener")
+	if !ok {
+		t.Fatal("plugin wasn't loaded")
+	}
+
+	err = db.CreateGuild("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = enablePlugin("1", "listener")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handleCoreEvent(&discordgo.Session{}, events.PollVoted{GuildID: "1", Option: 2})
+	// The plugin isn't enabled in this guild, so it shouldn't get the event
+	handleCoreEvent(&discordgo.Session{}, events.PollVoted{GuildID: "2", Option: 3})
+	// Nothing is subscribed to this event
+	handleCoreEvent(&discordgo.Session{}, events.TicketOpened{GuildID: "1"})
+
+	var votes []string
+	err = <-callOnLoop(plugin.api, func(vm *goja.Runtime) error {
+		return vm.ExportTo(vm.Get("votes"), &votes)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(votes) != 1 || votes[0] != "1:2" {
+		t.Errorf("expected only the vote in the guild the plugin is enabled in, got %q", votes)
+	}
+}
+
+func TestEventGuildID(t *testing.T) {
+	cases := []struct {
+		name    string
+		event   any
+		guildID string
+	}{
+		{"field", events.PollVoted{GuildID: "1"}, "1"},
+		{"pointer", &discordgo.GuildMemberAdd{Member: &discordgo.Member{GuildID: "2"}}, "2"},
+		{"guild", &discordgo.GuildCreate{Guild: &discordgo.Guild{ID: "3"}}, "3"},
+		{"nil embedded pointer", &discordgo.MessageCreate{}, ""},
+		{"none", &discordgo.Ready{}, ""},
+		{"not a struct", "4", ""},
+	}
+
+	for _, c := range cases {
+		if guildID := eventGuildID(c.event); guildID != c.guildID {
+			t.Errorf("%s: expected guild ID %q, got %q", c.name, c.guildID, guildID)
+		}
+	}
+}
+
+func TestDispatchEventSlowHandler(t *testing.T) {
+	err := db.Init(context.Background(), "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	t.Cleanup(func() { cfg = Config{} })
+
+	dir := t.TempDir()
+	writePlugin := func(file, src string) {
+		err := os.WriteFile(filepath.Join(dir, file), []byte(src), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	writePlugin("slow.js", `
+owobot.pluginInfo = {name: "slow", version: "1", desc: "d"}
+owobot.on("MessageCreate", function(s, m) { while (true) {} })
+`)
+	writePlugin("fast.js", `
+owobot.pluginInfo = {name: "fast", version: "1", desc: "d"}
+var messages = []
+owobot.on("MessageCreate", function(s, m) { messages.push(m.content) })
+`)
+
+	err = Load(dir, Config{CallTimeout: util.Duration(100 * time.Millisecond)}, &discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Start(&discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, plugin := range allPlugins() {
+			unload(plugin)
+		}
+	})
+
+	err = db.CreateGuild("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"slow", "fast"} {
+		err = enablePlugin("1", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	slow, ok := findPlugin("slow")
+	if !ok {
+		t.Fatal("slow plugin wasn't loaded")
+	}
+	fast, ok := findPlugin("fast")
+	if !ok {
+		t.Fatal("fast plugin wasn't loaded")
+	}
+
+	start := time.Now()
+	handlePluginEvent(&discordgo.Session{}, &discordgo.MessageCreate{Message: &discordgo.Message{GuildID: "1", Content: "hi"}})
+	if elapsed := time.Since(start); elapsed >= time.Duration(cfg.CallTimeout) {
+		t.Errorf("dispatching the event waited %s for the slow handler", elapsed)
+	}
+
+	var messages []string
+	err = <-callOnLoop(fast.api, func(vm *goja.Runtime) error {
+		return vm.ExportTo(vm.Get("messages"), &messages)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0] != "hi" {
+		t.Errorf("expected the fast plugin to get the message, got %q", messages)
+	}
+
+	// The slow handler is interrupted once its deadline passes,
+	// after which the slow plugin's loop can run other calls.
+	err = <-callOnLoop(slow.api, func(vm *goja.Runtime) error { return nil })
+	if errors.Is(err, errLoopBlocked) {
+		t.Error("the slow handler wasn't interrupted")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,186 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dop251/goja"
+	"go.elara.ws/logger/log"
+	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/systems/eventlog"
+)
+
+const (
+	// maxRecentErrors is how many of the most recent errors are kept for each plugin in each guild
+	maxRecentErrors = 5
+	// errorLogInterval is the minimum amount of time between errors forwarded
+	// to a guild's event log for the same plugin, so a broken plugin can't flood it.
+	errorLogInterval = time.Minute
+)
+
+// healthKey identifies a plugin's health statistics in a specific guild
+type healthKey struct {
+	plugin  string
+	guildID string
+}
+
+// pluginError represents an error that occurred in a plugin handler
+type pluginError struct {
+	Time    time.Time
+	Source  string
+	Message string
+	Stack   string
+}
+
+// pluginHealth contains statistics about the calls made to a plugin's handlers
+type pluginHealth struct {
+	Invocations  int64
+	Errors       int64
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+	// Recent contains the most recent errors, oldest first
+	Recent []pluginError
+
+	lastForwarded time.Time
+}
+
+// AvgLatency returns the average time the plugin's handlers took to run
+func (ph pluginHealth) AvgLatency() time.Duration {
+	if ph.Invocations == 0 {
+		return 0
+	}
+	return ph.TotalLatency / time.Duration(ph.Invocations)
+}
+
+var (
+	healthMtx = sync.Mutex{}
+	health    = map[healthKey]*pluginHealth{}
+)
+
+// invoke calls fn on the plugin's event loop like [callOnLoop], recording the
+// call's latency and any error it returns in the plugin's health statistics for
+// the given guild. Source describes the handler being called, e.g. "event MessageCreate".
+func invoke(oa *owobotAPI, guildID, source string, fn func(vm *goja.Runtime) error) <-chan error {
+	return callOnLoop(oa, func(vm *goja.Runtime) error {
+		oa.guildID = guildID
+		defer func() { oa.guildID = "" }()
+
+		start := time.Now()
+		err := fn(vm)
+		recordCall(oa, guildID, source, time.Since(start), err)
+		if err != nil && oa.onError != nil {
+			oa.onError(source, err)
+		}
+		return err
+	})
+}
+
+// recordCall adds a call to a plugin's health statistics. If the call failed and
+// the guild has plugin error forwarding enabled, the error is also sent to the
+// guild's event log.
+func recordCall(oa *owobotAPI, guildID, source string, latency time.Duration, err error) {
+	healthMtx.Lock()
+	defer healthMtx.Unlock()
+
+	key := healthKey{plugin: oa.PluginInfo.Name, guildID: guildID}
+	ph, ok := health[key]
+	if !ok {
+		ph = &pluginHealth{}
+		health[key] = ph
+	}
+
+	ph.Invocations++
+	ph.TotalLatency += latency
+	ph.MaxLatency = max(ph.MaxLatency, latency)
+
+	if err == nil {
+		return
+	}
+
+	ph.Errors++
+	pe := newPluginError(source, err)
+	ph.Recent = append(ph.Recent, pe)
+	if len(ph.Recent) > maxRecentErrors {
+		ph.Recent = ph.Recent[len(ph.Recent)-maxRecentErrors:]
+	}
+
+	if guildID != "" && time.Since(ph.lastForwarded) >= errorLogInterval {
+		ph.lastForwarded = time.Now()
+		// Forwarding makes API requests, so it shouldn't block the plugin's event loop
+		go forwardError(oa.sess, guildID, oa.PluginInfo.Name, pe)
+	}
+}
+
+// newPluginError creates a pluginError from an error returned by a plugin
+// handler, including the JavaScript stack trace if there is one.
+func newPluginError(source string, err error) pluginError {
+	pe := pluginError{Time: time.Now(), Source: source, Message: err.Error()}
+
+	var ex *goja.Exception
+	if errors.As(err, &ex) {
+		if val := ex.Value(); val != nil {
+			pe.Message = val.String()
+		}
+		stack := strings.TrimSpace(strings.TrimPrefix(ex.String(), pe.Message))
+		pe.Stack = strings.ReplaceAll(stack, "\n\t", "\n")
+	}
+
+	return pe
+}
+
+// forwardError writes a plugin error to the guild's event log if
+// the guild has plugin error forwarding enabled.
+func forwardError(s *discordgo.Session, guildID, pluginName string, pe pluginError) {
+	guild, err := db.GuildByID(guildID)
+	if err != nil {
+		log.Warn("Error getting guild").Str("guild-id", guildID).Err(err).Send()
+		return
+	} else if !guild.PluginErrorLog {
+		return
+	}
+
+	err = eventlog.Log(s, guildID, eventlog.Entry{
+		Title:       "Plugin error",
+		Description: fmt.Sprintf("The %q plugin failed while handling %s:\n%s", pluginName, pe.Source, formatPluginError(pe)),
+	})
+	if err != nil {
+		log.Warn("Error writing plugin error to event log").Str("guild-id", guildID).Err(err).Send()
+	}
+}
+
+// healthStats returns a copy of a plugin's health statistics in the given guild
+func healthStats(pluginName, guildID string) pluginHealth {
+	healthMtx.Lock()
+	defer healthMtx.Unlock()
+
+	ph, ok := health[healthKey{plugin: pluginName, guildID: guildID}]
+	if !ok {
+		return pluginHealth{}
+	}
+
+	out := *ph
+	out.Recent = append([]pluginError(nil), ph.Recent...)
+	return out
+}
+
+// formatPluginError formats an error and its stack trace for display in Discord
+func formatPluginError(pe pluginError) string {
+	out := pe.Message
+	if pe.Stack != "" {
+		out += "\n" + pe.Stack
+	}
+	// Leave room for the code block and the surrounding text in embeds
+	return "```\n" + truncate(out, 900) + "\n```"
+}
+
+// truncate shortens s to at most n bytes, marking where it was cut off
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n-3], "") + "..."
+}
@@ -3,25 +3,26 @@ package plugins
 import (
 	"errors"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/dop251/goja"
 	"github.com/dop251/goja_nodejs/eventloop"
+	"github.com/dop251/goja_nodejs/require"
 	"go.elara.ws/logger/log"
 	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/events"
+	"go.elara.ws/owobot/internal/shards"
 	"go.elara.ws/owobot/internal/systems/commands"
 	"go.elara.ws/owobot/internal/systems/plugins/builtins"
 	"go.elara.ws/owobot/internal/util"
 )
 
 func Init(s *discordgo.Session) error {
-	if err := loadEnabled(); err != nil {
-		return err
-	}
-
 	commands.Register(s, pluginCmd, &discordgo.ApplicationCommand{
 		Name:        "plugin",
 		Description: "Interact with the plugins on this server",
@@ -93,103 +94,380 @@ func Init(s *discordgo.Session) error {
 					},
 				},
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "config",
+				Description: "View or change a plugin's settings in this guild",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "plugin",
+						Description: "The name of the plugin to configure",
+						Required:    true,
+					},
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "setting",
+						Description:  "The setting to change. If not provided, all settings will be shown.",
+						Autocomplete: true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "value",
+						Description: "The new value of the setting. If not provided, the current value will be shown.",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "reset",
+						Description: "Reset the setting to its default value",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "reload",
+				Description: "Reload plugins from their files (bot owner only)",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "plugin",
+						Description: "The name of the plugin to reload. If not provided, all plugins will be reloaded.",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "status",
+				Description: "View the health of the plugins enabled in this guild",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "plugin",
+						Description: "The name of the plugin to view recent errors for. If not provided, all enabled plugins will be shown.",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "errorlog",
+				Description: "Choose whether plugin errors are sent to the event log channel",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "enabled",
+						Description: "Whether plugin errors should be sent to the event log channel",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "install",
+				Description: "Install or update a plugin from the plugin registry (bot owner only)",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "The name of the plugin to install",
+						Required:    true,
+					},
+				},
+			},
 		},
 	})
 
-	s.AddHandler(handleAutocomplete)
-	s.AddHandler(handlePluginEvent)
+	commands.AddInteractionHandler(handleAutocomplete)
+	shards.AddHandler(handlePluginEvent)
+	commands.AddInteractionHandler(handlePluginInteraction)
+	events.Subscribe(handleCoreEvent)
+	commands.AddInteractionHandler(util.InteractionErrorHandler("plugin-component", handleComponent))
+	commands.AddInteractionHandler(util.InteractionErrorHandler("pluginadm-enable-confirm", onEnableConfirm))
+
+	// Plugins are loaded before the session is opened, so their
+	// capabilities can only be checked against the approved ones now.
+	for _, plugin := range allPlugins() {
+		revokeGrownCapabilities(s, plugin)
+	}
+	syncPluginGuilds(s)
 	return nil
 }
 
-// Load recursively loads plugins from the given directory.
-func Load(dir string, sess *discordgo.Session) error {
-	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+// pending contains the plugins that were loaded by [Load] and are waiting
+// to be initialized by [Start], in dependency order.
+var pending []*Plugin
 
-		if d.IsDir() || filepath.Ext(path) != ".js" {
-			return nil
-		}
+// Load recursively loads plugins from the given directory using the given
+// configuration. The plugins aren't initialized until [Start] is called, so
+// that the intents they require are known before the connection to discord
+// is opened, while their init functions run once it's open.
+func Load(dir string, config Config, sess *discordgo.Session) error {
+	cfg = config
+	pluginDir = dir
+	validateLogLevels()
+
+	// The guilds plugins are enabled in have to be known while the
+	// plugins are loaded, so that their per-guild tables are migrated.
+	err := loadEnabled()
+	if err != nil {
+		return err
+	}
+
+	paths, err := pluginPaths(dir)
+	if err != nil {
+		return err
+	}
 
-		data, err := os.ReadFile(path)
+	// A plugin that can't be loaded shouldn't stop the others from loading,
+	// so failed plugins are logged and skipped, along with their dependents.
+	var loaded []*Plugin
+	for _, path := range paths {
+		plugin, err := loadPlugin(path, sess)
 		if err != nil {
-			return err
+			log.Error("Error loading plugin").Str("path", path).Err(err).Send()
+		} else if plugin != nil {
+			loaded = append(loaded, plugin)
 		}
+	}
 
-		loop := eventloop.NewEventLoop()
+	// Plugins are initialized in dependency order, so that plugins
+	// can rely on their dependencies being ready in their init function.
+	ordered, failed := sortByDependencies(loaded)
+	for plugin, err := range failed {
+		discard(plugin)
+		log.Error("Error loading plugin").Str("plugin", plugin.Info.Name).Err(err).Send()
+	}
+	pending = ordered
 
-		loop.Run(func(vm *goja.Runtime) {
-			vm.SetFieldNameMapper(lowerCamelNameMapper{})
-		})
+	return nil
+}
 
-		api := &owobotAPI{loop: loop, path: path}
+// Start initializes the plugins loaded by [Load] and starts watching the
+// plugin directory, running scheduled jobs, and refreshing the enabled plugins.
+// It should be called once the connection to discord has been opened.
+func Start(sess *discordgo.Session) error {
+	failedInit := map[string]bool{}
+	for _, plugin := range pending {
+		if i := slices.IndexFunc(plugin.Info.Dependencies, func(dep string) bool { return failedInit[dep] }); i != -1 {
+			failedInit[plugin.Info.Name] = true
+			discard(plugin)
+			log.Error("Error loading plugin").
+				Str("plugin", plugin.Info.Name).
+				Err(fmt.Errorf("dependency %q failed to initialize", plugin.Info.Dependencies[i])).
+				Send()
+			continue
+		}
 
-		loop.Run(func(vm *goja.Runtime) {
-			err = errors.Join(
-				vm.GlobalObject().Set("owobot", api),
-				vm.GlobalObject().Set("discord", builtins.Constants),
-				vm.GlobalObject().Set("print", fmt.Println),
-			)
-		})
+		err := initPlugin(plugin, sess)
 		if err != nil {
-			return err
+			// initPlugin has already discarded the plugin
+			failedInit[plugin.Info.Name] = true
+			log.Error("Error initializing plugin").Str("plugin", plugin.Info.Name).Err(err).Send()
+			continue
 		}
+		addPlugin(plugin)
+	}
+	pending = nil
 
-		loop.Start()
-		errCh := make(chan error)
+	if cfg.WatchInterval > 0 {
+		go watch(pluginDir, sess)
+	}
 
-		loop.RunOnLoop(func(vm *goja.Runtime) {
-			_, err := vm.RunScript(path, string(data))
-			errCh <- err
-		})
-		if err := <-errCh; err != nil {
-			return err
-		}
+	go runScheduler()
+	go refreshEnabled()
 
-		if !api.PluginInfo.IsValid() {
-			log.Warn("Plugin info not provided, skipping.").Str("path", path).Send()
-			return nil
+	return nil
+}
+
+// loadAndInitPlugin loads the plugin at the given path and initializes it,
+// making sure all of its dependencies have already been loaded. If the plugin
+// doesn't provide any plugin information, loadAndInitPlugin returns a nil plugin.
+func loadAndInitPlugin(path string, sess *discordgo.Session) (*Plugin, error) {
+	plugin, err := loadPlugin(path, sess)
+	if err != nil || plugin == nil {
+		return nil, err
+	}
+
+	for _, dep := range plugin.Info.Dependencies {
+		if _, ok := findPlugin(dep); !ok {
+			discard(plugin)
+			return nil, fmt.Errorf("%s: dependency %q is not loaded", plugin.Info.Name, dep)
 		}
+	}
 
-		prev, _ := db.GetPlugin(api.PluginInfo.Name)
+	return plugin, initPlugin(plugin, sess)
+}
 
-		err = db.AddPlugin(api.PluginInfo)
+// loadPlugin runs the plugin at the given path. The path may be a single
+// JavaScript file or a directory containing a multi-file plugin. The plugin
+// isn't initialized until [initPlugin] is called. If the plugin doesn't provide
+// any plugin information, loadPlugin returns a nil plugin.
+func loadPlugin(path string, sess *discordgo.Session) (plugin *Plugin, err error) {
+	// Single-file plugins may only require modules from the shared library
+	// directory, while multi-file plugins may also require their own modules.
+	entry, roots := path, []string{cfg.LibDir}
+	if isPluginDir(path) {
+		m, err := readManifest(path)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("%s: %w", path, err)
 		}
+		entry = filepath.Join(path, m.Main)
+		roots = append(roots, path)
+	}
 
-		loop.RunOnLoop(func(vm *goja.Runtime) {
-			err := builtins.Register(vm, api.PluginInfo.Name, api.PluginInfo.Version)
-			errCh <- err
-		})
-		if err := <-errCh; err != nil {
-			return err
+	data, err := os.ReadFile(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	registryOpts := []require.Option{require.WithLoader(sourceLoader(roots...))}
+	if cfg.LibDir != "" {
+		registryOpts = append(registryOpts, require.WithGlobalFolders(absPath(cfg.LibDir)))
+	}
+
+	loop := eventloop.NewEventLoop()
+
+	loop.Run(func(vm *goja.Runtime) {
+		vm.SetFieldNameMapper(lowerCamelNameMapper{})
+		require.NewRegistry(registryOpts...).Enable(vm)
+	})
+
+	api := &owobotAPI{loop: loop, path: path, sess: sess}
+
+	var runtime *goja.Runtime
+	loop.Run(func(vm *goja.Runtime) {
+		runtime = vm
+		err = errors.Join(
+			vm.GlobalObject().Set("owobot", api),
+			vm.GlobalObject().Set("discord", builtins.Constants),
+			vm.GlobalObject().Set("log", logAPI{oa: api}),
+			vm.GlobalObject().Set("print", logAPI{oa: api}.print),
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	startLoop(api, runtime)
+	defer func() {
+		// If the plugin couldn't be loaded, make sure we don't leave
+		// its loop or any of its handlers behind.
+		if plugin == nil {
+			stopLoop(api)
+			removeHandlers(api)
 		}
+	}()
 
-		Plugins = append(Plugins, Plugin{
-			Info:     api.PluginInfo,
-			Commands: api.Commands,
-			Loop:     loop,
-			api:      api,
+	err = <-callOnLoop(api, func(vm *goja.Runtime) error {
+		// The entry point's absolute path is used as the script name
+		// so that require can resolve modules relative to it.
+		_, err := vm.RunScript(absPath(entry), string(data))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !api.PluginInfo.IsValid() {
+		log.Warn("Plugin info not provided, skipping.").Str("path", path).Send()
+		return nil, nil
+	}
+
+	// Colons separate plugin names from the rest of namespaced custom IDs
+	if strings.Contains(api.PluginInfo.Name, ":") {
+		return nil, fmt.Errorf("%s: plugin names may not contain colons", api.PluginInfo.Name)
+	}
+
+	err = validateSettings(api.PluginInfo.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", api.PluginInfo.Name, err)
+	}
+
+	if disallowed := disallowedIntents(api.PluginInfo.Intents); disallowed != 0 {
+		log.Warn("Plugin requires privileged intents that aren't allowed by the configuration").
+			Str("plugin", api.PluginInfo.Name).
+			Str("intents", intentNames(disallowed)).
+			Send()
+	}
+
+	// Migrations are applied before the new version of the plugin is recorded,
+	// so a plugin whose migration failed is never recorded as upgraded.
+	err = migrate(api.PluginInfo, api.Migrations)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", api.PluginInfo.Name, err)
+	}
+
+	prev, _ := db.GetPlugin(api.PluginInfo.Name)
+
+	err = db.AddPlugin(api.PluginInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	var release func()
+	err = <-callOnLoop(api, func(vm *goja.Runtime) (err error) {
+		release, err = builtins.Register(vm, loop, api.PluginInfo, builtins.Limits{
+			FetchTimeout:     time.Duration(cfg.FetchTimeout),
+			FetchMaxBodySize: cfg.FetchMaxBodySize,
+			FetchMaxCookies:  cfg.FetchMaxCookies,
+			FetchHosts:       cfg.FetchAllowlist[api.PluginInfo.Name],
+			SQLMaxRows:       cfg.SQLMaxRows,
+			OnExceeded:       api.limitExceeded,
+		}, builtins.Guilds{
+			Enabled: api.Enabled,
+			Check:   api.checkGuild,
+			Current: func() string { return api.guildID },
 		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		if api.Init != nil {
-			callableInit, ok := goja.AssertFunction(api.Init)
-			if !ok {
-				log.Warn("Init value is not callable, ignoring.").Str("plugin", api.PluginInfo.Name).Send()
-				return nil
-			}
-
-			loop.RunOnLoop(func(vm *goja.Runtime) {
-				_, err := callableInit(vm.ToValue(api), vm.ToValue(prev), vm.ToValue(sess))
-				errCh <- err
-			})
-			if err := <-errCh; err != nil {
-				return fmt.Errorf("%s init: %w", api.PluginInfo.Name, err)
-			}
-		}
+	return &Plugin{
+		Info:          api.PluginInfo,
+		Commands:      api.Commands,
+		SlashCommands: api.SlashCommands,
+		Loop:          loop,
+		path:          path,
+		api:           api,
+		prev:          prev,
+		release:       release,
+	}, nil
+}
+
+// initPlugin calls the init function of a loaded plugin. If it fails,
+// the plugin is discarded.
+func initPlugin(plugin *Plugin, sess *discordgo.Session) error {
+	api := plugin.api
+	if api.Init == nil {
+		return nil
+	}
 
+	callableInit, ok := goja.AssertFunction(api.Init)
+	if !ok {
+		log.Warn("Init value is not callable, ignoring.").Str("plugin", api.PluginInfo.Name).Send()
 		return nil
+	}
+
+	err := <-callOnLoop(api, func(vm *goja.Runtime) error {
+		_, err := callableInit(vm.ToValue(api), vm.ToValue(plugin.prev), vm.ToValue(api.session(sess)))
+		return err
 	})
+	if err != nil {
+		discard(plugin)
+		return fmt.Errorf("%s init: %w", api.PluginInfo.Name, err)
+	}
+
+	// The init function may have changed the plugin's commands
+	plugin.Commands = api.Commands
+	plugin.SlashCommands = api.SlashCommands
+	return nil
+}
+
+// discard stops a plugin that was loaded but never added to the
+// plugin list and removes any handlers it registered.
+func discard(plugin *Plugin) {
+	stopLoop(plugin.api)
+	plugin.release()
+	removeHandlers(plugin.api)
 }
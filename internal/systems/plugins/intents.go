@@ -0,0 +1,81 @@
+package plugins
+
+import (
+	"math/bits"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/logger/log"
+	"go.elara.ws/owobot/internal/systems/plugins/builtins"
+)
+
+// privilegedIntents contains the intents that have to be enabled
+// for the bot in the Discord developer portal before they can be used.
+const privilegedIntents = discordgo.IntentGuildMembers | discordgo.IntentGuildPresences | discordgo.IntentMessageContent
+
+// intentsByName maps the names of the intents exported to plugins to their values
+var intentsByName = builtins.Constants["Intent"].(map[string]discordgo.Intent)
+
+// Intents returns the gateway intents required by all the loaded plugins. Privileged
+// intents that aren't allowed by the configuration are left out, since Discord refuses
+// connections that request privileged intents the bot doesn't have access to.
+func Intents() discordgo.Intent {
+	var out discordgo.Intent
+	for _, plugin := range allPlugins() {
+		out |= plugin.Info.Intents
+	}
+	// Intents are requested before the loaded plugins are started
+	for _, plugin := range pending {
+		out |= plugin.Info.Intents
+	}
+	return out &^ disallowedIntents(out)
+}
+
+// allowedPrivilegedIntents returns the privileged intents allowed by the configuration
+func allowedPrivilegedIntents() discordgo.Intent {
+	var out discordgo.Intent
+	for _, name := range cfg.PrivilegedIntents {
+		intent, ok := intentsByName[name]
+		if !ok || intent&^privilegedIntents != 0 {
+			log.Warn("Ignoring unknown privileged intent in configuration").Str("intent", name).Send()
+			continue
+		}
+		out |= intent
+	}
+	return out
+}
+
+// disallowedIntents returns the privileged intents in intents that aren't allowed by the configuration
+func disallowedIntents(intents discordgo.Intent) discordgo.Intent {
+	return intents & privilegedIntents &^ allowedPrivilegedIntents()
+}
+
+// missingIntents returns the intents a plugin requires that the session
+// wasn't opened with, such as intents required by a plugin that was loaded
+// after owobot started, or privileged intents that aren't allowed.
+func missingIntents(s *discordgo.Session, plugin *Plugin) discordgo.Intent {
+	return plugin.Info.Intents &^ s.Identify.Intents
+}
+
+// intentNames returns a comma-separated list of the names of the given intents
+func intentNames(intents discordgo.Intent) string {
+	var names []string
+	for intents != 0 {
+		bit := discordgo.Intent(1) << bits.TrailingZeros64(uint64(intents))
+		intents &^= bit
+
+		name := ""
+		for n, intent := range intentsByName {
+			// Some intents have more than one name, so the
+			// alphabetically first one is used to be consistent.
+			if intent == bit && (name == "" || n < name) {
+				name = n
+			}
+		}
+		if name == "" {
+			name = "unknown"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,72 @@
+package plugins
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"go.elara.ws/logger/log"
+	"go.elara.ws/owobot/internal/systems/eventlog"
+)
+
+// limitExceeded records a resource limit violation for the plugin. If the plugin
+// has exceeded its limits too many times within the configured window, it's
+// disabled in every guild where it's enabled.
+func (oa *owobotAPI) limitExceeded(err error) {
+	log.Warn("Plugin exceeded a resource limit").Str("plugin", oa.PluginInfo.Name).Err(err).Send()
+
+	if cfg.MaxViolations <= 0 {
+		return
+	}
+
+	oa.mu.Lock()
+	defer oa.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-time.Duration(cfg.ViolationWindow))
+	oa.violations = slices.DeleteFunc(oa.violations, func(t time.Time) bool {
+		return t.Before(cutoff)
+	})
+	oa.violations = append(oa.violations, now)
+
+	if len(oa.violations) >= cfg.MaxViolations {
+		oa.violations = nil
+		// This may be called on the plugin's event loop, and disabling
+		// the plugin calls its onDisable hook on the loop, so it has to
+		// happen in a separate goroutine to avoid a deadlock.
+		go oa.disableEverywhere(err)
+	}
+}
+
+// disableEverywhere disables the plugin in every guild where it's enabled
+// and notifies each guild's event log that it was disabled.
+func (oa *owobotAPI) disableEverywhere(cause error) {
+	plugin, ok := findPlugin(oa.PluginInfo.Name)
+	if !ok || plugin.api != oa {
+		// The plugin has been reloaded or unloaded since the violation
+		return
+	}
+
+	log.Warn("Plugin repeatedly exceeded its resource limits, disabling").Str("plugin", plugin.Info.Name).Send()
+
+	for _, guildID := range guildsWithPlugins(plugin.Info.Name) {
+		err := disable(oa.sess, guildID, plugin)
+		if err != nil {
+			log.Warn("Error disabling plugin").Str("plugin", plugin.Info.Name).Str("guild-id", guildID).Err(err).Send()
+		}
+
+		err = eventlog.Log(oa.sess, guildID, eventlog.Entry{
+			Title: "Plugin disabled",
+			Description: fmt.Sprintf(
+				"The %q plugin was automatically disabled because it exceeded its resource limits %d times within %s. The last violation was: %s",
+				plugin.Info.Name,
+				cfg.MaxViolations,
+				time.Duration(cfg.ViolationWindow),
+				cause,
+			),
+		})
+		if err != nil {
+			log.Warn("Error writing plugin notice to event log").Str("guild-id", guildID).Err(err).Send()
+		}
+	}
+}
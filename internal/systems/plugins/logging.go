@@ -0,0 +1,125 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.elara.ws/logger"
+	"go.elara.ws/logger/log"
+)
+
+// Logger is the logger used for messages logged by plugins. Plugin messages are
+// filtered according to each plugin's log level rather than the logger's level.
+// If it's nil, the core logger is used.
+var Logger logger.Logger
+
+// logAPI is the JavaScript API that lets plugins write log messages. Messages
+// automatically include the plugin's name, and the guild whose event or
+// command is currently being handled, if there is one.
+type logAPI struct {
+	oa *owobotAPI
+}
+
+func (l logAPI) Debug(msg string, fields map[string]any) {
+	l.log(logger.LogLevelDebug, msg, fields)
+}
+
+func (l logAPI) Info(msg string, fields map[string]any) {
+	l.log(logger.LogLevelInfo, msg, fields)
+}
+
+func (l logAPI) Warn(msg string, fields map[string]any) {
+	l.log(logger.LogLevelWarn, msg, fields)
+}
+
+func (l logAPI) Error(msg string, fields map[string]any) {
+	l.log(logger.LogLevelError, msg, fields)
+}
+
+// log writes a message at the given level if the plugin's log level allows it
+func (l logAPI) log(level logger.LogLevel, msg string, fields map[string]any) {
+	name := l.oa.PluginInfo.Name
+	if name == "" {
+		// The plugin info hasn't been set yet
+		name = l.oa.path
+	}
+
+	if level < pluginLogLevel(name) {
+		return
+	}
+
+	lg := Logger
+	if lg == nil {
+		lg = log.Logger
+	}
+
+	var lb logger.LogBuilder
+	switch level {
+	case logger.LogLevelDebug:
+		lb = lg.Debug(msg)
+	case logger.LogLevelInfo:
+		lb = lg.Info(msg)
+	case logger.LogLevelWarn:
+		lb = lg.Warn(msg)
+	default:
+		lb = lg.Error(msg)
+	}
+
+	lb = lb.Str("plugin", name)
+	if l.oa.guildID != "" {
+		lb = lb.Str("guild", l.oa.guildID)
+	}
+	for _, key := range sortedKeys(fields) {
+		lb = addField(lb, key, fields[key])
+	}
+	lb.Send()
+}
+
+// addField adds a field with a value from a plugin to lb. The logger panics on
+// values it can't encode, such as functions, NaN, or cyclic objects, so the value
+// is encoded here first, and values that can't be encoded are replaced with a
+// description of the error.
+func addField(lb logger.LogBuilder, key string, val any) logger.LogBuilder {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return lb.Str(key, fmt.Sprintf("<%s>", err))
+	}
+	return lb.Any(key, json.RawMessage(data))
+}
+
+// print logs its arguments at the info level, separated by spaces
+func (l logAPI) print(args ...any) {
+	msg := fmt.Sprintln(args...)
+	l.log(logger.LogLevelInfo, strings.TrimSuffix(msg, "\n"), nil)
+}
+
+// pluginLogLevel returns the minimum level of the messages logged by the given plugin
+func pluginLogLevel(pluginName string) logger.LogLevel {
+	name, ok := cfg.LogLevels[pluginName]
+	if !ok {
+		name = cfg.LogLevel
+	}
+
+	level, err := logger.ParseLogLevel(name)
+	if err != nil {
+		return logger.LogLevelInfo
+	}
+	return level
+}
+
+// validateLogLevels warns about invalid plugin log levels in the configuration
+func validateLogLevels() {
+	if _, err := logger.ParseLogLevel(cfg.LogLevel); err != nil {
+		log.Warn("Invalid plugin log level in configuration, using info").Str("level", cfg.LogLevel).Send()
+	}
+
+	for pluginName, name := range cfg.LogLevels {
+		if _, err := logger.ParseLogLevel(name); err != nil {
+			log.Warn("Invalid plugin log level in configuration, using info").
+				Str("plugin", pluginName).
+				Str("level", name).
+				Send()
+		}
+	}
+}
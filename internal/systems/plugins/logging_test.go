@@ -0,0 +1,63 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dop251/goja"
+	"go.elara.ws/logger"
+	"go.elara.ws/owobot/internal/db"
+)
+
+func TestLogUnencodableFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	prev := Logger
+	Logger = logger.NewJSON(buf)
+	t.Cleanup(func() { Logger = prev })
+
+	vm := goja.New()
+	vm.SetFieldNameMapper(lowerCamelNameMapper{})
+	api := &owobotAPI{PluginInfo: db.PluginInfo{Name: "test"}}
+	vm.Set("log", logAPI{oa: api})
+
+	_, err := vm.RunString(`
+		const cyclic = {}
+		cyclic.self = cyclic
+		log.info("function", {f: function() {}})
+		log.info("nan", {n: NaN, inf: Infinity})
+		log.info("cyclic", {c: cyclic})
+		log.info("valid", {a: [1, 2], b: "x"})
+	`)
+	if err != nil {
+		t.Fatalf("logging unencodable values failed: %s", err)
+	}
+
+	out := buf.String()
+	for _, msg := range []string{"function", "nan", "cyclic"} {
+		if !strings.Contains(out, `"msg":"`+msg+`"`) {
+			t.Errorf("expected a %q message in the output: %s", msg, out)
+		}
+	}
+
+	if !strings.Contains(out, `"a":[1,2],"b":"x"`) {
+		t.Errorf("expected valid fields to be encoded as JSON: %s", out)
+	}
+}
+
+func TestAddField(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lg := logger.NewJSON(buf)
+	addField(lg.Info("msg"), "f", func() {}).Send()
+
+	var event map[string]any
+	err := json.Unmarshal(buf.Bytes(), &event)
+	if err != nil {
+		t.Fatalf("invalid output %q: %s", buf.String(), err)
+	}
+
+	if s, ok := event["f"].(string); !ok || !strings.Contains(s, "unsupported type") {
+		t.Errorf("expected the field to contain the encoding error, got %v", event["f"])
+	}
+}
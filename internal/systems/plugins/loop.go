@@ -0,0 +1,176 @@
+package plugins
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+	"go.elara.ws/logger/log"
+)
+
+var (
+	// errCallTimeout is used to interrupt plugin functions that run past their deadline
+	errCallTimeout = errors.New("plugin call exceeded its deadline")
+	// errLoopBlocked is returned for calls that the plugin's event loop didn't run in time
+	errLoopBlocked = errors.New("plugin's event loop didn't run the call in time")
+)
+
+// loopStopTimeout is how long stopLoop waits for a plugin's event loop to
+// stop if there's no call timeout to derive the wait from.
+const loopStopTimeout = 30 * time.Second
+
+// waitTimeout returns how long to wait for a call on a plugin's event loop to
+// complete. A call may have to wait for the watchdog to notice and interrupt
+// a job that's blocking the loop before it can run, which can take up to two
+// call timeouts, and then run for a call timeout itself. If the call timeout
+// is disabled, zero is returned and calls are waited for indefinitely.
+func waitTimeout() time.Duration {
+	return 3 * time.Duration(cfg.CallTimeout)
+}
+
+// callOnLoop schedules fn to run on the given plugin's event loop and returns
+// a channel that will receive its error once it completes. The channel is buffered,
+// so callers that don't care about the result may simply discard it without
+// blocking the loop. Calls that run past their deadline count as limit violations.
+// If the loop doesn't complete the call within [waitTimeout], the channel receives
+// errLoopBlocked instead, so callers never wait on a stuck or stopped loop forever.
+func callOnLoop(oa *owobotAPI, fn func(vm *goja.Runtime) error) <-chan error {
+	errCh := make(chan error, 1)
+	// Only the first result is sent, whether it's the call's error or the timeout.
+	send := func(err error) {
+		select {
+		case errCh <- err:
+		default:
+		}
+	}
+
+	if wait := waitTimeout(); wait > 0 {
+		time.AfterFunc(wait, func() { send(errLoopBlocked) })
+	}
+
+	oa.loop.RunOnLoop(func(vm *goja.Runtime) {
+		err := withDeadline(vm, time.Duration(cfg.CallTimeout), errCallTimeout, func() error {
+			return fn(vm)
+		})
+		if errors.Is(err, errCallTimeout) {
+			oa.limitExceeded(err)
+		}
+		send(err)
+	})
+	return errCh
+}
+
+// startLoop starts a plugin's event loop along with its watchdog
+func startLoop(oa *owobotAPI, vm *goja.Runtime) {
+	oa.stopWatchdog = make(chan struct{})
+	oa.loop.Start()
+	go watchLoop(oa, vm, oa.stopWatchdog)
+}
+
+// stopLoop stops a plugin's event loop and its watchdog. If the loop is
+// blocked by a job that can't be interrupted, such as a native function
+// that never returns, it gives up after a while and abandons the loop
+// rather than blocking the caller forever.
+func stopLoop(oa *owobotAPI) {
+	// The watchdog keeps running until the loop stops,
+	// so that it can interrupt a job that's blocking it.
+	defer close(oa.stopWatchdog)
+
+	timeout := waitTimeout()
+	if timeout <= 0 {
+		timeout = loopStopTimeout
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		oa.loop.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		log.Warn("Plugin's event loop didn't stop in time, abandoning it").Str("path", oa.path).Send()
+	}
+}
+
+// watchLoop interrupts any job that blocks a plugin's event loop for longer
+// than the call timeout. Calls made through callOnLoop have their own deadline,
+// but other jobs, such as timer callbacks and the callbacks of asynchronous
+// builtins, don't, so the watchdog regularly runs a heartbeat on the loop and
+// interrupts the runtime if it doesn't run in time. It returns when stop is closed.
+func watchLoop(oa *owobotAPI, vm *goja.Runtime, stop <-chan struct{}) {
+	timeout := time.Duration(cfg.CallTimeout)
+	if timeout <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(timeout)
+	defer ticker.Stop()
+
+	for {
+		done := make(chan struct{})
+		oa.loop.RunOnLoop(func(vm *goja.Runtime) {
+			// Nothing else is running on the loop at this point, so an interrupt
+			// that's still set was meant for a job that has already returned.
+			vm.ClearInterrupt()
+			close(done)
+		})
+
+		select {
+		case <-done:
+		case <-stop:
+			return
+		case <-time.After(timeout):
+			vm.Interrupt(errCallTimeout)
+			oa.limitExceeded(errCallTimeout)
+
+			select {
+			case <-done:
+			case <-stop:
+				return
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// withDeadline runs fn, interrupting the runtime with cause if it takes longer than
+// timeout. It must be called on the runtime's event loop. If timeout is zero or
+// negative, fn is allowed to run for as long as it needs.
+func withDeadline(vm *goja.Runtime, timeout time.Duration, cause error, fn func() error) error {
+	if timeout <= 0 {
+		return fn()
+	}
+
+	var (
+		mu   sync.Mutex
+		done bool
+	)
+
+	timer := time.AfterFunc(timeout, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		// Only interrupt if fn is still running, otherwise we'd interrupt
+		// whatever runs on the loop next.
+		if !done {
+			vm.Interrupt(cause)
+		}
+	})
+
+	err := fn()
+
+	mu.Lock()
+	done = true
+	mu.Unlock()
+	timer.Stop()
+	vm.ClearInterrupt()
+
+	return err
+}
@@ -0,0 +1,90 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dop251/goja"
+	"github.com/dop251/goja_nodejs/eventloop"
+	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/util"
+)
+
+func TestWatchdogInterruptsTimerCallbacks(t *testing.T) {
+	err := db.Init(context.Background(), "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	t.Cleanup(func() { cfg = Config{} })
+
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "spinner.js"), []byte(`
+owobot.pluginInfo = {name: "spinner", version: "1", desc: "d"}
+owobot.init = function() {
+	setTimeout(function() { while (true) {} }, 0)
+}
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Load(dir, Config{CallTimeout: util.Duration(50 * time.Millisecond)}, &discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Start(&discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plugin, ok := findPlugin("spinner")
+	if !ok {
+		t.Fatal("plugin wasn't loaded")
+	}
+
+	// Give the timer a chance to start spinning
+	time.Sleep(10 * time.Millisecond)
+
+	err = <-invoke(plugin.api, "1", "test", func(vm *goja.Runtime) error { return nil })
+	if errors.Is(err, errLoopBlocked) {
+		t.Fatal("call wasn't run because the timer callback blocked the loop")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+
+	unloaded := make(chan struct{})
+	go func() {
+		unload(plugin)
+		close(unloaded)
+	}()
+
+	select {
+	case <-unloaded:
+	case <-time.After(time.Second):
+		t.Fatal("unloading the plugin didn't return")
+	}
+}
+
+func TestCallOnLoopTimesOut(t *testing.T) {
+	cfg = Config{CallTimeout: util.Duration(10 * time.Millisecond)}
+	t.Cleanup(func() { cfg = Config{} })
+
+	// The loop is never started, so the call can't run
+	oa := &owobotAPI{loop: eventloop.NewEventLoop()}
+
+	select {
+	case err := <-callOnLoop(oa, func(vm *goja.Runtime) error { return nil }):
+		if !errors.Is(err, errLoopBlocked) {
+			t.Errorf("expected errLoopBlocked, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiting on a loop that isn't running didn't time out")
+	}
+}
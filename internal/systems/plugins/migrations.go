@@ -0,0 +1,90 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/systems/plugins/builtins"
+)
+
+// Migration is a versioned SQL migration declared by a plugin
+type Migration struct {
+	Version string
+	SQL     string
+}
+
+// migrate applies the plugin's migrations that haven't been applied yet,
+// in the order they were declared. Each migration runs in its own transaction.
+// If one fails, the remaining migrations aren't applied. For plugins with
+// per-guild tables, the migrations are applied in every guild where the
+// plugin is enabled.
+func migrate(info db.PluginInfo, migrations []Migration) error {
+	if len(migrations) == 0 {
+		return nil
+	}
+
+	if !info.Capabilities.SQL {
+		return errors.New("plugin declares migrations but not the \"sql\" capability")
+	}
+
+	seen := map[string]bool{}
+	for _, m := range migrations {
+		if m.Version == "" {
+			return errors.New("migration versions must not be empty")
+		} else if seen[m.Version] {
+			return fmt.Errorf("migration %q is declared more than once", m.Version)
+		}
+		seen[m.Version] = true
+	}
+
+	if !info.GuildTables {
+		return migrateTables(info.Name, "", migrations)
+	}
+
+	for _, guildID := range guildsWithPlugins(info.Name) {
+		err := migrateTables(info.Name, guildID, migrations)
+		if err != nil {
+			return fmt.Errorf("guild %s: %w", guildID, err)
+		}
+	}
+	return nil
+}
+
+// migrateGuild applies the migrations of a plugin with per-guild tables
+// in the given guild. It's used when the plugin is enabled in a new guild.
+func migrateGuild(plugin *Plugin, guildID string) error {
+	if !plugin.Info.GuildTables {
+		return nil
+	}
+	return migrateTables(plugin.Info.Name, guildID, plugin.api.Migrations)
+}
+
+// migrateTables applies the migrations that haven't been applied yet to the
+// plugin's tables. If guildID isn't empty, the tables that belong to the plugin
+// in that guild are migrated.
+func migrateTables(pluginName, guildID string, migrations []Migration) error {
+	applied, err := db.AppliedPluginMigrations(pluginName, guildID)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if slices.Contains(applied, m.Version) {
+			continue
+		}
+
+		query, err := builtins.ModifyQuery(pluginName, guildID, m.SQL)
+		if err != nil {
+			return fmt.Errorf("migration %q: %w", m.Version, err)
+		}
+
+		err = db.ApplyPluginMigration(pluginName, guildID, m.Version, query)
+		if err != nil {
+			return fmt.Errorf("migration %q: %w", m.Version, err)
+		}
+	}
+
+	return nil
+}
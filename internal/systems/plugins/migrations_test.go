@@ -0,0 +1,86 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/systems/plugins/builtins"
+)
+
+const guildTablesPlugin = `
+owobot.pluginInfo = {
+	name: "counter",
+	version: "1",
+	desc: "d",
+	capabilities: {sql: true},
+	guildTables: true,
+}
+owobot.migrations = [{version: "1", sql: "CREATE TABLE counts (n INT)"}]
+`
+
+func TestLoadMigratesEnabledGuilds(t *testing.T) {
+	err := db.Init(context.Background(), "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	err = db.CreateGuild("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.EnablePlugin("1", "counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "counter.js"), []byte(guildTablesPlugin), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Load(dir, Config{}, &discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Start(&discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, plugin := range allPlugins() {
+			unload(plugin)
+		}
+	})
+
+	query, err := builtins.ModifyQuery("counter", "1", "SELECT * FROM counts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.DB().Exec(query)
+	if err != nil {
+		t.Errorf("guild table wasn't created when the plugin was loaded: %s", err)
+	}
+}
+
+func TestGuildTableNamesDontCollide(t *testing.T) {
+	guild, err := builtins.ModifyQuery("foo", "1", "SELECT * FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shared, err := builtins.ModifyQuery("foo_1", "", "SELECT * FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if guild == shared {
+		t.Errorf("plugin foo in guild 1 and plugin foo_1 use the same tables: %s", guild)
+	}
+}
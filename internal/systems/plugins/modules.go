@@ -0,0 +1,165 @@
+package plugins
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja_nodejs/require"
+)
+
+// manifestName is the name of the manifest file that marks
+// a directory as a multi-file plugin.
+const manifestName = "plugin.json"
+
+// manifest represents a multi-file plugin's manifest
+type manifest struct {
+	// Main is the path of the plugin's entry point,
+	// relative to the plugin directory.
+	Main string `json:"main"`
+}
+
+// readManifest reads the manifest of the multi-file plugin in dir
+func readManifest(dir string) (manifest, error) {
+	m := manifest{Main: "index.js"}
+
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if err != nil {
+		return m, err
+	}
+
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return m, err
+	}
+
+	if !filepath.IsLocal(m.Main) {
+		return m, errors.New("plugin entry point must be inside the plugin directory")
+	}
+
+	return m, nil
+}
+
+// isPluginDir checks whether the given directory contains a multi-file plugin
+func isPluginDir(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, manifestName))
+	return err == nil
+}
+
+// pluginPaths returns the paths of all the plugins in dir. Directories
+// with a manifest are multi-file plugins, and any other JavaScript files
+// are single-file plugins. The shared library directory is skipped.
+func pluginPaths(dir string) ([]string, error) {
+	libDir := absPath(cfg.LibDir)
+
+	var out []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Hidden files are skipped, which includes the
+		// temporary files used when installing plugins.
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			if libDir != "" && absPath(path) == libDir {
+				return fs.SkipDir
+			} else if isPluginDir(path) {
+				out = append(out, path)
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if filepath.Ext(path) == ".js" {
+			out = append(out, path)
+		}
+
+		return nil
+	})
+	return out, err
+}
+
+// latestModTime returns the latest modification time of the file at path,
+// or of any file inside it if it's a directory. If path doesn't exist,
+// the zero time is returned.
+func latestModTime(path string) (time.Time, error) {
+	var out time.Time
+	err := filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if modTime := info.ModTime(); modTime.After(out) {
+			out = modTime
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	return out, err
+}
+
+// sourceLoader returns a require source loader that only allows
+// loading modules from inside the given root directories.
+func sourceLoader(roots ...string) require.SourceLoader {
+	for i, root := range roots {
+		roots[i] = resolvePath(root)
+	}
+
+	return func(path string) ([]byte, error) {
+		path = resolvePath(filepath.FromSlash(path))
+		if !slices.ContainsFunc(roots, func(root string) bool {
+			return root != "" && withinDir(root, path)
+		}) {
+			return nil, require.ModuleFileDoesNotExistError
+		}
+		return require.DefaultSourceLoader(path)
+	}
+}
+
+// withinDir checks whether path is inside dir
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// absPath returns the absolute version of path, or an empty
+// string if path is empty.
+func absPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
+
+// resolvePath returns the absolute version of path with any symlinks
+// resolved, so that symlinks can't be used to escape a directory.
+func resolvePath(path string) string {
+	path = absPath(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
@@ -0,0 +1,735 @@
+// Code generated by gen_paramnames.go. DO NOT EDIT.
+
+package plugins
+
+// paramNames maps the methods that may be exposed to plugins to the
+// names of their parameters, keyed by import path, receiver, and name.
+var paramNames = map[string][]string{
+	"bufio.Reader.Discard":                                   {"n"},
+	"bufio.Reader.Peek":                                      {"n"},
+	"bufio.Reader.Read":                                      {"p"},
+	"bufio.Reader.ReadBytes":                                 {"delim"},
+	"bufio.Reader.ReadSlice":                                 {"delim"},
+	"bufio.Reader.ReadString":                                {"delim"},
+	"bufio.Reader.Reset":                                     {"r"},
+	"bufio.Reader.WriteTo":                                   {"w"},
+	"bufio.Scanner.Buffer":                                   {"buf", "max"},
+	"bufio.Scanner.Split":                                    {"split"},
+	"bufio.Writer.ReadFrom":                                  {"r"},
+	"bufio.Writer.Reset":                                     {"w"},
+	"bufio.Writer.Write":                                     {"p"},
+	"bufio.Writer.WriteByte":                                 {"c"},
+	"bufio.Writer.WriteRune":                                 {"r"},
+	"bufio.Writer.WriteString":                               {"s"},
+	"crypto/tls.CertificateRequestInfo.SupportsCertificate":  {"c"},
+	"crypto/tls.ClientHelloInfo.SupportsCertificate":         {"c"},
+	"crypto/tls.Config.DecryptTicket":                        {"identity", "cs"},
+	"crypto/tls.Config.EncryptTicket":                        {"cs", "ss"},
+	"crypto/tls.Config.SetSessionTicketKeys":                 {"keys"},
+	"crypto/tls.Conn.HandshakeContext":                       {"ctx"},
+	"crypto/tls.Conn.Read":                                   {"b"},
+	"crypto/tls.Conn.SetDeadline":                            {"t"},
+	"crypto/tls.Conn.SetReadDeadline":                        {"t"},
+	"crypto/tls.Conn.SetWriteDeadline":                       {"t"},
+	"crypto/tls.Conn.VerifyHostname":                         {"host"},
+	"crypto/tls.Conn.Write":                                  {"b"},
+	"crypto/tls.ConnectionState.ExportKeyingMaterial":        {"label", "context", "length"},
+	"crypto/tls.Dialer.Dial":                                 {"network", "addr"},
+	"crypto/tls.Dialer.DialContext":                          {"ctx", "network", "addr"},
+	"crypto/tls.QUICConn.HandleData":                         {"level", "data"},
+	"crypto/tls.QUICConn.SendSessionTicket":                  {"opts"},
+	"crypto/tls.QUICConn.SetTransportParameters":             {"params"},
+	"crypto/tls.QUICConn.Start":                              {"ctx"},
+	"crypto/tls.QUICConn.StoreSession":                       {"session"},
+	"crypto/x509.CertPool.AddCert":                           {"cert"},
+	"crypto/x509.CertPool.AddCertWithConstraint":             {"cert", "constraint"},
+	"crypto/x509.CertPool.AppendCertsFromPEM":                {"pemCerts"},
+	"crypto/x509.CertPool.Equal":                             {"other"},
+	"crypto/x509.Certificate.CheckCRLSignature":              {"crl"},
+	"crypto/x509.Certificate.CheckSignature":                 {"algo", "signed", "signature"},
+	"crypto/x509.Certificate.CheckSignatureFrom":             {"parent"},
+	"crypto/x509.Certificate.CreateCRL":                      {"rand", "priv", "revokedCerts", "now", "expiry"},
+	"crypto/x509.Certificate.Equal":                          {"other"},
+	"crypto/x509.Certificate.Verify":                         {"opts"},
+	"crypto/x509.Certificate.VerifyHostname":                 {"h"},
+	"crypto/x509.OID.AppendBinary":                           {"b"},
+	"crypto/x509.OID.AppendText":                             {"b"},
+	"crypto/x509.OID.Equal":                                  {"other"},
+	"crypto/x509.OID.EqualASN1OID":                           {"other"},
+	"crypto/x509.OID.UnmarshalBinary":                        {"b"},
+	"crypto/x509.OID.UnmarshalText":                          {"text"},
+	"crypto/x509.RevocationList.CheckSignatureFrom":          {"parent"},
+	"crypto/x509/pkix.CertificateList.HasExpired":            {"now"},
+	"crypto/x509/pkix.Name.FillFromRDNSequence":              {"rdns"},
+	"encoding/asn1.BitString.At":                             {"i"},
+	"encoding/asn1.ObjectIdentifier.Equal":                   {"other"},
+	"github.com/bwmarrin/discordgo.ActionsRow.UnmarshalJSON": {"data"},
+	"github.com/bwmarrin/discordgo.Activity.UnmarshalJSON":   {"b"},
+	"github.com/bwmarrin/discordgo.ApplicationCommandInteractionDataOption.ChannelValue": {"s"},
+	"github.com/bwmarrin/discordgo.ApplicationCommandInteractionDataOption.RoleValue":    {"s", "gID"},
+	"github.com/bwmarrin/discordgo.ApplicationCommandInteractionDataOption.UserValue":    {"s"},
+	"github.com/bwmarrin/discordgo.Bucket.Release":                                       {"headers"},
+	"github.com/bwmarrin/discordgo.Guild.BannerURL":                                      {"size"},
+	"github.com/bwmarrin/discordgo.Guild.IconURL":                                        {"size"},
+	"github.com/bwmarrin/discordgo.GuildPreview.IconURL":                                 {"size"},
+	"github.com/bwmarrin/discordgo.Interaction.UnmarshalJSON":                            {"raw"},
+	"github.com/bwmarrin/discordgo.InteractionCreate.UnmarshalJSON":                      {"b"},
+	"github.com/bwmarrin/discordgo.Member.AvatarURL":                                     {"size"},
+	"github.com/bwmarrin/discordgo.Message.ContentWithMoreMentionsReplaced":              {"s"},
+	"github.com/bwmarrin/discordgo.Message.UnmarshalJSON":                                {"data"},
+	"github.com/bwmarrin/discordgo.MessageCreate.UnmarshalJSON":                          {"b"},
+	"github.com/bwmarrin/discordgo.MessageDelete.UnmarshalJSON":                          {"b"},
+	"github.com/bwmarrin/discordgo.MessageEdit.SetContent":                               {"str"},
+	"github.com/bwmarrin/discordgo.MessageEdit.SetEmbed":                                 {"embed"},
+	"github.com/bwmarrin/discordgo.MessageEdit.SetEmbeds":                                {"embeds"},
+	"github.com/bwmarrin/discordgo.MessageUpdate.UnmarshalJSON":                          {"b"},
+	"github.com/bwmarrin/discordgo.ModalSubmitInteractionData.UnmarshalJSON":             {"data"},
+	"github.com/bwmarrin/discordgo.RateLimiter.GetBucket":                                {"key"},
+	"github.com/bwmarrin/discordgo.RateLimiter.GetWaitTime":                              {"b", "minRemaining"},
+	"github.com/bwmarrin/discordgo.RateLimiter.LockBucket":                               {"bucketID"},
+	"github.com/bwmarrin/discordgo.RateLimiter.LockBucketObject":                         {"b"},
+	"github.com/bwmarrin/discordgo.Role.IconURL":                                         {"size"},
+	"github.com/bwmarrin/discordgo.Roles.Less":                                           {"i", "j"},
+	"github.com/bwmarrin/discordgo.Roles.Swap":                                           {"i", "j"},
+	"github.com/bwmarrin/discordgo.Session.AddHandler":                                   {"handler"},
+	"github.com/bwmarrin/discordgo.Session.AddHandlerOnce":                               {"handler"},
+	"github.com/bwmarrin/discordgo.Session.Application":                                  {"appID"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationAssets":                            {"appID"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationBotCreate":                         {"appID"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationCommand":                           {"appID", "guildID", "cmdID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationCommandBulkOverwrite":              {"appID", "guildID", "commands", "options"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationCommandCreate":                     {"appID", "guildID", "cmd", "options"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationCommandDelete":                     {"appID", "guildID", "cmdID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationCommandEdit":                       {"appID", "guildID", "cmdID", "cmd", "options"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationCommandPermissions":                {"appID", "guildID", "cmdID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationCommandPermissionsBatchEdit":       {"appID", "guildID", "permissions", "options"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationCommandPermissionsEdit":            {"appID", "guildID", "cmdID", "permissions", "options"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationCommands":                          {"appID", "guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationCreate":                            {"ap"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationDelete":                            {"appID"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationRoleConnectionMetadata":            {"appID"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationRoleConnectionMetadataUpdate":      {"appID", "metadata"},
+	"github.com/bwmarrin/discordgo.Session.ApplicationUpdate":                            {"appID", "ap"},
+	"github.com/bwmarrin/discordgo.Session.AutoModerationRule":                           {"guildID", "ruleID", "options"},
+	"github.com/bwmarrin/discordgo.Session.AutoModerationRuleCreate":                     {"guildID", "rule", "options"},
+	"github.com/bwmarrin/discordgo.Session.AutoModerationRuleDelete":                     {"guildID", "ruleID", "options"},
+	"github.com/bwmarrin/discordgo.Session.AutoModerationRuleEdit":                       {"guildID", "ruleID", "rule", "options"},
+	"github.com/bwmarrin/discordgo.Session.AutoModerationRules":                          {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.Channel":                                      {"channelID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelDelete":                                {"channelID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelEdit":                                  {"channelID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelEditComplex":                           {"channelID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelFileSend":                              {"channelID", "name", "r", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelFileSendWithMessage":                   {"channelID", "content", "name", "r", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelInviteCreate":                          {"channelID", "i", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelInvites":                               {"channelID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessage":                               {"channelID", "messageID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageCrosspost":                      {"channelID", "messageID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageDelete":                         {"channelID", "messageID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageEdit":                           {"channelID", "messageID", "content", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageEditComplex":                    {"m", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageEditEmbed":                      {"channelID", "messageID", "embed", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageEditEmbeds":                     {"channelID", "messageID", "embeds", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessagePin":                            {"channelID", "messageID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageSend":                           {"channelID", "content", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageSendComplex":                    {"channelID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageSendEmbed":                      {"channelID", "embed", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageSendEmbedReply":                 {"channelID", "embed", "reference", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageSendEmbeds":                     {"channelID", "embeds", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageSendEmbedsReply":                {"channelID", "embeds", "reference", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageSendReply":                      {"channelID", "content", "reference", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageSendTTS":                        {"channelID", "content", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessageUnpin":                          {"channelID", "messageID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessages":                              {"channelID", "limit", "beforeID", "afterID", "aroundID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessagesBulkDelete":                    {"channelID", "messages", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelMessagesPinned":                        {"channelID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelNewsFollow":                            {"channelID", "targetID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelPermissionDelete":                      {"channelID", "targetID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelPermissionSet":                         {"channelID", "targetID", "targetType", "allow", "deny", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelTyping":                                {"channelID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ChannelVoiceJoin":                             {"gID", "cID", "mute", "deaf"},
+	"github.com/bwmarrin/discordgo.Session.ChannelVoiceJoinManual":                       {"gID", "cID", "mute", "deaf"},
+	"github.com/bwmarrin/discordgo.Session.ChannelWebhooks":                              {"channelID", "options"},
+	"github.com/bwmarrin/discordgo.Session.CloseWithCode":                                {"closeCode"},
+	"github.com/bwmarrin/discordgo.Session.FollowupMessageCreate":                        {"interaction", "wait", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.FollowupMessageDelete":                        {"interaction", "messageID", "options"},
+	"github.com/bwmarrin/discordgo.Session.FollowupMessageEdit":                          {"interaction", "messageID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.ForumThreadStart":                             {"channelID", "name", "archiveDuration", "content", "options"},
+	"github.com/bwmarrin/discordgo.Session.ForumThreadStartComplex":                      {"channelID", "threadData", "messageData", "options"},
+	"github.com/bwmarrin/discordgo.Session.ForumThreadStartEmbed":                        {"channelID", "name", "archiveDuration", "embed", "options"},
+	"github.com/bwmarrin/discordgo.Session.ForumThreadStartEmbeds":                       {"channelID", "name", "archiveDuration", "embeds", "options"},
+	"github.com/bwmarrin/discordgo.Session.Gateway":                                      {"options"},
+	"github.com/bwmarrin/discordgo.Session.GatewayBot":                                   {"options"},
+	"github.com/bwmarrin/discordgo.Session.Guild":                                        {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildApplicationCommandsPermissions":          {"appID", "guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildAuditLog":                                {"guildID", "userID", "beforeID", "actionType", "limit", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildBan":                                     {"guildID", "userID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildBanCreate":                               {"guildID", "userID", "days", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildBanCreateWithReason":                     {"guildID", "userID", "reason", "days", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildBanDelete":                               {"guildID", "userID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildBans":                                    {"guildID", "limit", "beforeID", "afterID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildChannelCreate":                           {"guildID", "name", "ctype", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildChannelCreateComplex":                    {"guildID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildChannels":                                {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildChannelsReorder":                         {"guildID", "channels", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildCreate":                                  {"name", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildCreateWithTemplate":                      {"templateCode", "name", "icon", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildDelete":                                  {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildEdit":                                    {"guildID", "g", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildEmbed":                                   {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildEmbedEdit":                               {"guildID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildEmoji":                                   {"guildID", "emojiID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildEmojiCreate":                             {"guildID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildEmojiDelete":                             {"guildID", "emojiID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildEmojiEdit":                               {"guildID", "emojiID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildEmojis":                                  {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildIcon":                                    {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildIntegrationCreate":                       {"guildID", "integrationType", "integrationID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildIntegrationDelete":                       {"guildID", "integrationID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildIntegrationEdit":                         {"guildID", "integrationID", "expireBehavior", "expireGracePeriod", "enableEmoticons", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildIntegrations":                            {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildInvites":                                 {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildLeave":                                   {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMember":                                  {"guildID", "userID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMemberAdd":                               {"guildID", "userID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMemberDeafen":                            {"guildID", "userID", "deaf", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMemberDelete":                            {"guildID", "userID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMemberDeleteWithReason":                  {"guildID", "userID", "reason", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMemberEdit":                              {"guildID", "userID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMemberEditComplex":                       {"guildID", "userID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMemberMove":                              {"guildID", "userID", "channelID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMemberMute":                              {"guildID", "userID", "mute", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMemberNickname":                          {"guildID", "userID", "nickname", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMemberRoleAdd":                           {"guildID", "userID", "roleID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMemberRoleRemove":                        {"guildID", "userID", "roleID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMemberTimeout":                           {"guildID", "userID", "until", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMembers":                                 {"guildID", "after", "limit", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildMembersSearch":                           {"guildID", "query", "limit", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildOnboarding":                              {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildOnboardingEdit":                          {"guildID", "o", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildPreview":                                 {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildPrune":                                   {"guildID", "days", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildPruneCount":                              {"guildID", "days", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildRoleCreate":                              {"guildID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildRoleDelete":                              {"guildID", "roleID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildRoleEdit":                                {"guildID", "roleID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildRoleReorder":                             {"guildID", "roles", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildRoles":                                   {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildScheduledEvent":                          {"guildID", "eventID", "userCount", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildScheduledEventCreate":                    {"guildID", "event", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildScheduledEventDelete":                    {"guildID", "eventID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildScheduledEventEdit":                      {"guildID", "eventID", "event", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildScheduledEventUsers":                     {"guildID", "eventID", "limit", "withMember", "beforeID", "afterID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildScheduledEvents":                         {"guildID", "userCount", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildSplash":                                  {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildTemplate":                                {"templateCode", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildTemplateCreate":                          {"guildID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildTemplateDelete":                          {"guildID", "templateCode", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildTemplateEdit":                            {"guildID", "templateCode", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildTemplateSync":                            {"guildID", "templateCode", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildTemplates":                               {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildThreadsActive":                           {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildWebhooks":                                {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.GuildWithCounts":                              {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.InteractionRespond":                           {"interaction", "resp", "options"},
+	"github.com/bwmarrin/discordgo.Session.InteractionResponse":                          {"interaction", "options"},
+	"github.com/bwmarrin/discordgo.Session.InteractionResponseDelete":                    {"interaction", "options"},
+	"github.com/bwmarrin/discordgo.Session.InteractionResponseEdit":                      {"interaction", "newresp", "options"},
+	"github.com/bwmarrin/discordgo.Session.Invite":                                       {"inviteID", "options"},
+	"github.com/bwmarrin/discordgo.Session.InviteAccept":                                 {"inviteID", "options"},
+	"github.com/bwmarrin/discordgo.Session.InviteComplex":                                {"inviteID", "guildScheduledEventID", "withCounts", "withExpiration", "options"},
+	"github.com/bwmarrin/discordgo.Session.InviteDelete":                                 {"inviteID", "options"},
+	"github.com/bwmarrin/discordgo.Session.InviteWithCounts":                             {"inviteID", "options"},
+	"github.com/bwmarrin/discordgo.Session.MessageReactionAdd":                           {"channelID", "messageID", "emojiID", "options"},
+	"github.com/bwmarrin/discordgo.Session.MessageReactionRemove":                        {"channelID", "messageID", "emojiID", "userID", "options"},
+	"github.com/bwmarrin/discordgo.Session.MessageReactions":                             {"channelID", "messageID", "emojiID", "limit", "beforeID", "afterID", "options"},
+	"github.com/bwmarrin/discordgo.Session.MessageReactionsRemoveAll":                    {"channelID", "messageID", "options"},
+	"github.com/bwmarrin/discordgo.Session.MessageReactionsRemoveEmoji":                  {"channelID", "messageID", "emojiID", "options"},
+	"github.com/bwmarrin/discordgo.Session.MessageThreadStart":                           {"channelID", "messageID", "name", "archiveDuration", "options"},
+	"github.com/bwmarrin/discordgo.Session.MessageThreadStartComplex":                    {"channelID", "messageID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.Request":                                      {"method", "urlStr", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.RequestGuildMembers":                          {"guildID", "query", "limit", "nonce", "presences"},
+	"github.com/bwmarrin/discordgo.Session.RequestGuildMembersBatch":                     {"guildIDs", "query", "limit", "nonce", "presences"},
+	"github.com/bwmarrin/discordgo.Session.RequestGuildMembersBatchList":                 {"guildIDs", "userIDs", "limit", "nonce", "presences"},
+	"github.com/bwmarrin/discordgo.Session.RequestGuildMembersList":                      {"guildID", "userIDs", "limit", "nonce", "presences"},
+	"github.com/bwmarrin/discordgo.Session.RequestWithBucketID":                          {"method", "urlStr", "data", "bucketID", "options"},
+	"github.com/bwmarrin/discordgo.Session.RequestWithLockedBucket":                      {"method", "urlStr", "contentType", "b", "bucket", "sequence", "options"},
+	"github.com/bwmarrin/discordgo.Session.StageInstance":                                {"channelID", "options"},
+	"github.com/bwmarrin/discordgo.Session.StageInstanceCreate":                          {"data", "options"},
+	"github.com/bwmarrin/discordgo.Session.StageInstanceDelete":                          {"channelID", "options"},
+	"github.com/bwmarrin/discordgo.Session.StageInstanceEdit":                            {"channelID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.ThreadJoin":                                   {"id", "options"},
+	"github.com/bwmarrin/discordgo.Session.ThreadLeave":                                  {"id", "options"},
+	"github.com/bwmarrin/discordgo.Session.ThreadMember":                                 {"threadID", "memberID", "withMember", "options"},
+	"github.com/bwmarrin/discordgo.Session.ThreadMemberAdd":                              {"threadID", "memberID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ThreadMemberRemove":                           {"threadID", "memberID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ThreadMembers":                                {"threadID", "limit", "withMember", "afterID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ThreadStart":                                  {"channelID", "name", "typ", "archiveDuration", "options"},
+	"github.com/bwmarrin/discordgo.Session.ThreadStartComplex":                           {"channelID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.ThreadsActive":                                {"channelID", "options"},
+	"github.com/bwmarrin/discordgo.Session.ThreadsArchived":                              {"channelID", "before", "limit", "options"},
+	"github.com/bwmarrin/discordgo.Session.ThreadsPrivateArchived":                       {"channelID", "before", "limit", "options"},
+	"github.com/bwmarrin/discordgo.Session.ThreadsPrivateJoinedArchived":                 {"channelID", "before", "limit", "options"},
+	"github.com/bwmarrin/discordgo.Session.UpdateCustomStatus":                           {"state"},
+	"github.com/bwmarrin/discordgo.Session.UpdateGameStatus":                             {"idle", "name"},
+	"github.com/bwmarrin/discordgo.Session.UpdateListeningStatus":                        {"name"},
+	"github.com/bwmarrin/discordgo.Session.UpdateStatusComplex":                          {"usd"},
+	"github.com/bwmarrin/discordgo.Session.UpdateStreamingStatus":                        {"idle", "name", "url"},
+	"github.com/bwmarrin/discordgo.Session.UpdateWatchStatus":                            {"idle", "name"},
+	"github.com/bwmarrin/discordgo.Session.User":                                         {"userID", "options"},
+	"github.com/bwmarrin/discordgo.Session.UserApplicationRoleConnection":                {"appID"},
+	"github.com/bwmarrin/discordgo.Session.UserApplicationRoleConnectionUpdate":          {"appID", "rconn"},
+	"github.com/bwmarrin/discordgo.Session.UserAvatar":                                   {"userID", "options"},
+	"github.com/bwmarrin/discordgo.Session.UserAvatarDecode":                             {"u", "options"},
+	"github.com/bwmarrin/discordgo.Session.UserChannelCreate":                            {"recipientID", "options"},
+	"github.com/bwmarrin/discordgo.Session.UserChannelPermissions":                       {"userID", "channelID", "fetchOptions"},
+	"github.com/bwmarrin/discordgo.Session.UserConnections":                              {"options"},
+	"github.com/bwmarrin/discordgo.Session.UserGuildMember":                              {"guildID", "options"},
+	"github.com/bwmarrin/discordgo.Session.UserGuilds":                                   {"limit", "beforeID", "afterID", "withCounts", "options"},
+	"github.com/bwmarrin/discordgo.Session.UserUpdate":                                   {"username", "avatar", "options"},
+	"github.com/bwmarrin/discordgo.Session.VoiceRegions":                                 {"options"},
+	"github.com/bwmarrin/discordgo.Session.Webhook":                                      {"webhookID", "options"},
+	"github.com/bwmarrin/discordgo.Session.WebhookCreate":                                {"channelID", "name", "avatar", "options"},
+	"github.com/bwmarrin/discordgo.Session.WebhookDelete":                                {"webhookID", "options"},
+	"github.com/bwmarrin/discordgo.Session.WebhookDeleteWithToken":                       {"webhookID", "token", "options"},
+	"github.com/bwmarrin/discordgo.Session.WebhookEdit":                                  {"webhookID", "name", "avatar", "channelID", "options"},
+	"github.com/bwmarrin/discordgo.Session.WebhookEditWithToken":                         {"webhookID", "token", "name", "avatar", "options"},
+	"github.com/bwmarrin/discordgo.Session.WebhookExecute":                               {"webhookID", "token", "wait", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.WebhookMessage":                               {"webhookID", "token", "messageID", "options"},
+	"github.com/bwmarrin/discordgo.Session.WebhookMessageDelete":                         {"webhookID", "token", "messageID", "options"},
+	"github.com/bwmarrin/discordgo.Session.WebhookMessageEdit":                           {"webhookID", "token", "messageID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.WebhookThreadExecute":                         {"webhookID", "token", "wait", "threadID", "data", "options"},
+	"github.com/bwmarrin/discordgo.Session.WebhookWithToken":                             {"webhookID", "token", "options"},
+	"github.com/bwmarrin/discordgo.State.Channel":                                        {"channelID"},
+	"github.com/bwmarrin/discordgo.State.ChannelAdd":                                     {"channel"},
+	"github.com/bwmarrin/discordgo.State.ChannelRemove":                                  {"channel"},
+	"github.com/bwmarrin/discordgo.State.Emoji":                                          {"guildID", "emojiID"},
+	"github.com/bwmarrin/discordgo.State.EmojiAdd":                                       {"guildID", "emoji"},
+	"github.com/bwmarrin/discordgo.State.EmojisAdd":                                      {"guildID", "emojis"},
+	"github.com/bwmarrin/discordgo.State.Guild":                                          {"guildID"},
+	"github.com/bwmarrin/discordgo.State.GuildAdd":                                       {"guild"},
+	"github.com/bwmarrin/discordgo.State.GuildRemove":                                    {"guild"},
+	"github.com/bwmarrin/discordgo.State.Member":                                         {"guildID", "userID"},
+	"github.com/bwmarrin/discordgo.State.MemberAdd":                                      {"member"},
+	"github.com/bwmarrin/discordgo.State.MemberRemove":                                   {"member"},
+	"github.com/bwmarrin/discordgo.State.Message":                                        {"channelID", "messageID"},
+	"github.com/bwmarrin/discordgo.State.MessageAdd":                                     {"message"},
+	"github.com/bwmarrin/discordgo.State.MessageColor":                                   {"message"},
+	"github.com/bwmarrin/discordgo.State.MessagePermissions":                             {"message"},
+	"github.com/bwmarrin/discordgo.State.MessageRemove":                                  {"message"},
+	"github.com/bwmarrin/discordgo.State.OnInterface":                                    {"se", "i"},
+	"github.com/bwmarrin/discordgo.State.Presence":                                       {"guildID", "userID"},
+	"github.com/bwmarrin/discordgo.State.PresenceAdd":                                    {"guildID", "presence"},
+	"github.com/bwmarrin/discordgo.State.PresenceRemove":                                 {"guildID", "presence"},
+	"github.com/bwmarrin/discordgo.State.Role":                                           {"guildID", "roleID"},
+	"github.com/bwmarrin/discordgo.State.RoleAdd":                                        {"guildID", "role"},
+	"github.com/bwmarrin/discordgo.State.RoleRemove":                                     {"guildID", "roleID"},
+	"github.com/bwmarrin/discordgo.State.ThreadListSync":                                 {"tls"},
+	"github.com/bwmarrin/discordgo.State.ThreadMemberUpdate":                             {"mu"},
+	"github.com/bwmarrin/discordgo.State.ThreadMembersUpdate":                            {"tmu"},
+	"github.com/bwmarrin/discordgo.State.UserChannelPermissions":                         {"userID", "channelID"},
+	"github.com/bwmarrin/discordgo.State.UserColor":                                      {"userID", "channelID"},
+	"github.com/bwmarrin/discordgo.State.VoiceState":                                     {"guildID", "userID"},
+	"github.com/bwmarrin/discordgo.TimeStamps.UnmarshalJSON":                             {"b"},
+	"github.com/bwmarrin/discordgo.TooManyRequests.UnmarshalJSON":                        {"b"},
+	"github.com/bwmarrin/discordgo.User.AvatarURL":                                       {"size"},
+	"github.com/bwmarrin/discordgo.User.BannerURL":                                       {"size"},
+	"github.com/bwmarrin/discordgo.VoiceConnection.AddHandler":                           {"h"},
+	"github.com/bwmarrin/discordgo.VoiceConnection.ChangeChannel":                        {"channelID", "mute", "deaf"},
+	"github.com/bwmarrin/discordgo.VoiceConnection.Speaking":                             {"b"},
+	"github.com/gorilla/websocket.Conn.EnableWriteCompression":                           {"enable"},
+	"github.com/gorilla/websocket.Conn.NextWriter":                                       {"messageType"},
+	"github.com/gorilla/websocket.Conn.ReadJSON":                                         {"v"},
+	"github.com/gorilla/websocket.Conn.SetCloseHandler":                                  {"h"},
+	"github.com/gorilla/websocket.Conn.SetCompressionLevel":                              {"level"},
+	"github.com/gorilla/websocket.Conn.SetPingHandler":                                   {"h"},
+	"github.com/gorilla/websocket.Conn.SetPongHandler":                                   {"h"},
+	"github.com/gorilla/websocket.Conn.SetReadDeadline":                                  {"t"},
+	"github.com/gorilla/websocket.Conn.SetReadLimit":                                     {"limit"},
+	"github.com/gorilla/websocket.Conn.SetWriteDeadline":                                 {"t"},
+	"github.com/gorilla/websocket.Conn.WriteControl":                                     {"messageType", "data", "deadline"},
+	"github.com/gorilla/websocket.Conn.WriteJSON":                                        {"v"},
+	"github.com/gorilla/websocket.Conn.WriteMessage":                                     {"messageType", "data"},
+	"github.com/gorilla/websocket.Conn.WritePreparedMessage":                             {"pm"},
+	"github.com/gorilla/websocket.Dialer.Dial":                                           {"urlStr", "requestHeader"},
+	"github.com/gorilla/websocket.Dialer.DialContext":                                    {"ctx", "urlStr", "requestHeader"},
+	"github.com/gorilla/websocket.Upgrader.Upgrade":                                      {"w", "r", "responseHeader"},
+	"go.elara.ws/owobot/internal/db.PluginCapabilities.AllowsHost":                       {"host"},
+	"go.elara.ws/owobot/internal/db.StringSlice.Scan":                                    {"value"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.alias":                            {"ident"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.apply":                            {"prefix", "suffix"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.assignments":                      {"assignments"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.constraints":                      {"constraints"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.expr":                             {"expr"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.exprs":                            {"exprs"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.indexedColumns":                   {"cols"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.name":                             {"ident"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.orderingTerms":                    {"terms"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.returning":                        {"rc"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.selectStmt":                       {"stmt"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.source":                           {"src"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.statement":                        {"stmt"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.windowDefinition":                 {"wd"},
+	"go.elara.ws/owobot/internal/db/sqltabler.rewriter.with":                             {"wc"},
+	"go.elara.ws/owobot/internal/limiter.Limiter.Decrement":                              {"key"},
+	"go.elara.ws/owobot/internal/limiter.Limiter.IsDepleted":                             {"key"},
+	"go.elara.ws/owobot/internal/limiter.Limiter.IsWarning":                              {"key"},
+	"go.elara.ws/owobot/internal/systems/commands.responseInterceptor.RoundTrip":         {"req"},
+	"go.elara.ws/owobot/internal/systems/commands.responseInterceptor.expect":            {"id"},
+	"go.elara.ws/owobot/internal/systems/commands.responseInterceptor.forget":            {"id"},
+	"go.elara.ws/owobot/internal/systems/plugins.Plugin.findSlashCmd":                    {"name"},
+	"go.elara.ws/owobot/internal/systems/plugins.RegistryEntry.SignedMessage":            {"data"},
+	"go.elara.ws/owobot/internal/systems/plugins.capabilityTransport.RoundTrip":          {"req"},
+	"go.elara.ws/owobot/internal/systems/plugins.fakeDiscord.RoundTrip":                  {"req"},
+	"go.elara.ws/owobot/internal/systems/plugins.logAPI.Debug":                           {"msg", "fields"},
+	"go.elara.ws/owobot/internal/systems/plugins.logAPI.Error":                           {"msg", "fields"},
+	"go.elara.ws/owobot/internal/systems/plugins.logAPI.Info":                            {"msg", "fields"},
+	"go.elara.ws/owobot/internal/systems/plugins.logAPI.Warn":                            {"msg", "fields"},
+	"go.elara.ws/owobot/internal/systems/plugins.logAPI.log":                             {"level", "msg", "fields"},
+	"go.elara.ws/owobot/internal/systems/plugins.logAPI.print":                           {"args"},
+	"go.elara.ws/owobot/internal/systems/plugins.lowerCamelNameMapper.FieldName":         {"", "f"},
+	"go.elara.ws/owobot/internal/systems/plugins.lowerCamelNameMapper.MethodName":        {"", "m"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.ActionsRow":                   {"components"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.Button":                       {"b"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.CancelTimer":                  {"id"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.Config":                       {"guildID"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.Emit":                         {"name", "payload"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.Enabled":                      {"guildID"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.Modal":                        {"customID", "title", "components"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.On":                           {"eventType", "fn"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.OnComponent":                  {"prefix", "fn"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.OnModal":                      {"prefix", "fn"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.OnTimer":                      {"name", "fn"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.Respond":                      {"s", "i", "content"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.RespondEphemeral":             {"s", "i", "content"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.RunAt":                        {"name", "at", "payload"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.Schedule":                     {"name", "expr", "fn"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.SelectMenu":                   {"sm"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.TextInput":                    {"ti"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.addInteractionHandler":        {"prefix", "modal", "fn"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.checkGuild":                   {"guildID"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.disableEverywhere":            {"cause"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.findInteractionHandler":       {"id", "modal"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.guildPayload":                 {"payload"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.limitExceeded":                {"err"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.namespacedID":                 {"id"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.session":                      {"s"},
+	"go.elara.ws/owobot/internal/systems/plugins.owobotAPI.timerHandler":                 {"name"},
+	"go.elara.ws/owobot/internal/systems/plugins.typeGen.callbackType":                   {"recv", "member"},
+	"go.elara.ws/owobot/internal/systems/plugins.typeGen.fields":                         {"t"},
+	"go.elara.ws/owobot/internal/systems/plugins.typeGen.funcSignature":                  {"fn"},
+	"go.elara.ws/owobot/internal/systems/plugins.typeGen.methodOverride":                 {"t", "name"},
+	"go.elara.ws/owobot/internal/systems/plugins.typeGen.name":                           {"t"},
+	"go.elara.ws/owobot/internal/systems/plugins.typeGen.signature":                      {"t", "names"},
+	"go.elara.ws/owobot/internal/systems/plugins.typeGen.signatureFrom":                  {"t", "skip", "names", "recv", "method"},
+	"go.elara.ws/owobot/internal/systems/plugins.typeGen.tsType":                         {"t"},
+	"go.elara.ws/owobot/internal/systems/plugins.typeGen.valueType":                      {"v", "indent"},
+	"go.elara.ws/owobot/internal/systems/plugins.typeGen.writeInterface":                 {"w", "t"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.Guilds.check":                  {"guildID"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.Guilds.enabled":                {"guildID"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.Limits.exceeded":               {"format", "args"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.boundedJar.Cookies":            {"u"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.boundedJar.SetCookies":         {"u", "cookies"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.boundedJar.remove":             {"entry"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.boundedJar.stored":             {"entry", "c"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.cacheAPI.Channel":              {"s", "guildID", "channelID"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.cacheAPI.Member":               {"s", "guildID", "userID"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.cacheAPI.Role":                 {"s", "guildID", "roleID"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.cacheAPI.Roles":                {"s", "guildID"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.eventLogAPI.Log":               {"s", "guildID", "entry"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.fetcher.allowsHost":            {"host"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.fetcher.do":                    {"req", "opts"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.fetcher.fetch":                 {"url", "opts"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.fetcher.fetchAsync":            {"url", "opts"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.fetcher.newRequest":            {"url", "opts"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.fetcher.requestError":          {"req", "err"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.kvAPI.Delete":                  {"guildID", "key"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.kvAPI.Get":                     {"guildID", "key"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.kvAPI.List":                    {"guildID", "prefix"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.kvAPI.Set":                     {"guildID", "key", "value", "ttl"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.sqlAPI.Exec":                   {"query", "args"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.sqlAPI.Guild":                  {"guildID"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.sqlAPI.Prepare":                {"query"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.sqlAPI.Query":                  {"query", "args"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.sqlAPI.QueryOne":               {"query", "args"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.sqlAPI.Transaction":            {"fn"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.sqlAPI.modifyQuery":            {"query"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.sqlAPI.rowsToMap":              {"rows"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.sqlStmt.Exec":                  {"args"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.sqlStmt.Query":                 {"args"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.sqlStmt.QueryOne":              {"args"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.stmtSet.add":                   {"stmt"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.stmtSet.remove":                {"stmt"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.ticketsAPI.Close":              {"s", "guildID", "user", "executor"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.ticketsAPI.Open":               {"s", "guildID", "user", "executor"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.vercmpAPI.Compare":             {"v1", "v2"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.vercmpAPI.Equal":               {"v1", "v2"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.vercmpAPI.Newer":               {"v1", "v2"},
+	"go.elara.ws/owobot/internal/systems/plugins/builtins.vercmpAPI.Older":               {"v1", "v2"},
+	"go.elara.ws/owobot/internal/util.Duration.UnmarshalText":                            {"b"},
+	"go.elara.ws/owobot/internal/xsync.KeyedMutex.Lock":                                  {"key"},
+	"go.elara.ws/owobot/internal/xsync.KeyedMutex.Unlock":                                {"key"},
+	"math/big.Float.Abs":                                      {"x"},
+	"math/big.Float.Add":                                      {"x", "y"},
+	"math/big.Float.Append":                                   {"buf", "fmt", "prec"},
+	"math/big.Float.AppendText":                               {"b"},
+	"math/big.Float.Cmp":                                      {"y"},
+	"math/big.Float.Copy":                                     {"x"},
+	"math/big.Float.Format":                                   {"s", "format"},
+	"math/big.Float.GobDecode":                                {"buf"},
+	"math/big.Float.Int":                                      {"z"},
+	"math/big.Float.MantExp":                                  {"mant"},
+	"math/big.Float.Mul":                                      {"x", "y"},
+	"math/big.Float.Neg":                                      {"x"},
+	"math/big.Float.Parse":                                    {"s", "base"},
+	"math/big.Float.Quo":                                      {"x", "y"},
+	"math/big.Float.Rat":                                      {"z"},
+	"math/big.Float.Scan":                                     {"s", "ch"},
+	"math/big.Float.Set":                                      {"x"},
+	"math/big.Float.SetFloat64":                               {"x"},
+	"math/big.Float.SetInf":                                   {"signbit"},
+	"math/big.Float.SetInt":                                   {"x"},
+	"math/big.Float.SetInt64":                                 {"x"},
+	"math/big.Float.SetMantExp":                               {"mant", "exp"},
+	"math/big.Float.SetMode":                                  {"mode"},
+	"math/big.Float.SetPrec":                                  {"prec"},
+	"math/big.Float.SetRat":                                   {"x"},
+	"math/big.Float.SetString":                                {"s"},
+	"math/big.Float.SetUint64":                                {"x"},
+	"math/big.Float.Sqrt":                                     {"x"},
+	"math/big.Float.Sub":                                      {"x", "y"},
+	"math/big.Float.Text":                                     {"format", "prec"},
+	"math/big.Float.UnmarshalText":                            {"text"},
+	"math/big.Int.Abs":                                        {"x"},
+	"math/big.Int.Add":                                        {"x", "y"},
+	"math/big.Int.And":                                        {"x", "y"},
+	"math/big.Int.AndNot":                                     {"x", "y"},
+	"math/big.Int.Append":                                     {"buf", "base"},
+	"math/big.Int.AppendText":                                 {"b"},
+	"math/big.Int.Binomial":                                   {"n", "k"},
+	"math/big.Int.Bit":                                        {"i"},
+	"math/big.Int.Cmp":                                        {"y"},
+	"math/big.Int.CmpAbs":                                     {"y"},
+	"math/big.Int.Div":                                        {"x", "y"},
+	"math/big.Int.DivMod":                                     {"x", "y", "m"},
+	"math/big.Int.Divide":                                     {"x", "y", "r", "mode"},
+	"math/big.Int.Exp":                                        {"x", "y", "m"},
+	"math/big.Int.FillBytes":                                  {"buf"},
+	"math/big.Int.Format":                                     {"s", "ch"},
+	"math/big.Int.GCD":                                        {"x", "y", "a", "b"},
+	"math/big.Int.GobDecode":                                  {"buf"},
+	"math/big.Int.Lsh":                                        {"x", "n"},
+	"math/big.Int.Mod":                                        {"x", "y"},
+	"math/big.Int.ModInverse":                                 {"g", "n"},
+	"math/big.Int.ModSqrt":                                    {"x", "p"},
+	"math/big.Int.Mul":                                        {"x", "y"},
+	"math/big.Int.MulRange":                                   {"a", "b"},
+	"math/big.Int.Neg":                                        {"x"},
+	"math/big.Int.Not":                                        {"x"},
+	"math/big.Int.Or":                                         {"x", "y"},
+	"math/big.Int.ProbablyPrime":                              {"n"},
+	"math/big.Int.Quo":                                        {"x", "y"},
+	"math/big.Int.QuoRem":                                     {"x", "y", "r"},
+	"math/big.Int.Rand":                                       {"rnd", "n"},
+	"math/big.Int.Rem":                                        {"x", "y"},
+	"math/big.Int.Rsh":                                        {"x", "n"},
+	"math/big.Int.Scan":                                       {"s", "ch"},
+	"math/big.Int.Set":                                        {"x"},
+	"math/big.Int.SetBit":                                     {"x", "i", "b"},
+	"math/big.Int.SetBits":                                    {"abs"},
+	"math/big.Int.SetBytes":                                   {"buf"},
+	"math/big.Int.SetInt64":                                   {"x"},
+	"math/big.Int.SetString":                                  {"s", "base"},
+	"math/big.Int.SetUint64":                                  {"x"},
+	"math/big.Int.Sqrt":                                       {"x"},
+	"math/big.Int.Sub":                                        {"x", "y"},
+	"math/big.Int.Text":                                       {"base"},
+	"math/big.Int.UnmarshalJSON":                              {"text"},
+	"math/big.Int.UnmarshalText":                              {"text"},
+	"math/big.Int.Xor":                                        {"x", "y"},
+	"math/big.Rat.Abs":                                        {"x"},
+	"math/big.Rat.Add":                                        {"x", "y"},
+	"math/big.Rat.AppendText":                                 {"b"},
+	"math/big.Rat.Cmp":                                        {"y"},
+	"math/big.Rat.FloatString":                                {"prec"},
+	"math/big.Rat.GobDecode":                                  {"buf"},
+	"math/big.Rat.Inv":                                        {"x"},
+	"math/big.Rat.Mul":                                        {"x", "y"},
+	"math/big.Rat.Neg":                                        {"x"},
+	"math/big.Rat.Quo":                                        {"x", "y"},
+	"math/big.Rat.Scan":                                       {"s", "ch"},
+	"math/big.Rat.Set":                                        {"x"},
+	"math/big.Rat.SetFloat64":                                 {"f"},
+	"math/big.Rat.SetFrac":                                    {"a", "b"},
+	"math/big.Rat.SetFrac64":                                  {"a", "b"},
+	"math/big.Rat.SetInt":                                     {"x"},
+	"math/big.Rat.SetInt64":                                   {"x"},
+	"math/big.Rat.SetString":                                  {"s"},
+	"math/big.Rat.SetUint64":                                  {"x"},
+	"math/big.Rat.Sub":                                        {"x", "y"},
+	"math/big.Rat.UnmarshalText":                              {"text"},
+	"math/rand.Rand.Int31n":                                   {"n"},
+	"math/rand.Rand.Int63n":                                   {"n"},
+	"math/rand.Rand.Intn":                                     {"n"},
+	"math/rand.Rand.Perm":                                     {"n"},
+	"math/rand.Rand.Read":                                     {"p"},
+	"math/rand.Rand.Seed":                                     {"seed"},
+	"math/rand.Rand.Shuffle":                                  {"n", "swap"},
+	"mime/multipart.Part.Read":                                {"d"},
+	"mime/multipart.Reader.ReadForm":                          {"maxMemory"},
+	"mime/multipart.Writer.CreateFormField":                   {"fieldname"},
+	"mime/multipart.Writer.CreateFormFile":                    {"fieldname", "filename"},
+	"mime/multipart.Writer.CreatePart":                        {"header"},
+	"mime/multipart.Writer.SetBoundary":                       {"boundary"},
+	"mime/multipart.Writer.WriteField":                        {"fieldname", "value"},
+	"net.Buffers.Read":                                        {"p"},
+	"net.Buffers.WriteTo":                                     {"w"},
+	"net.Dialer.Dial":                                         {"network", "address"},
+	"net.Dialer.DialContext":                                  {"ctx", "network", "address"},
+	"net.Dialer.DialIP":                                       {"ctx", "network", "laddr", "raddr"},
+	"net.Dialer.DialTCP":                                      {"ctx", "network", "laddr", "raddr"},
+	"net.Dialer.DialUDP":                                      {"ctx", "network", "laddr", "raddr"},
+	"net.Dialer.DialUnix":                                     {"ctx", "network", "laddr", "raddr"},
+	"net.Dialer.SetMultipathTCP":                              {"use"},
+	"net.IP.AppendText":                                       {"b"},
+	"net.IP.Equal":                                            {"x"},
+	"net.IP.Mask":                                             {"mask"},
+	"net.IP.UnmarshalText":                                    {"text"},
+	"net.IPConn.ReadFrom":                                     {"b"},
+	"net.IPConn.ReadFromIP":                                   {"b"},
+	"net.IPConn.ReadMsgIP":                                    {"b", "oob"},
+	"net.IPConn.WriteMsgIP":                                   {"b", "oob", "addr"},
+	"net.IPConn.WriteTo":                                      {"b", "addr"},
+	"net.IPConn.WriteToIP":                                    {"b", "addr"},
+	"net.IPNet.Contains":                                      {"ip"},
+	"net.ListenConfig.Listen":                                 {"ctx", "network", "address"},
+	"net.ListenConfig.ListenPacket":                           {"ctx", "network", "address"},
+	"net.ListenConfig.SetMultipathTCP":                        {"use"},
+	"net.Resolver.LookupAddr":                                 {"ctx", "addr"},
+	"net.Resolver.LookupCNAME":                                {"ctx", "host"},
+	"net.Resolver.LookupHost":                                 {"ctx", "host"},
+	"net.Resolver.LookupIP":                                   {"ctx", "network", "host"},
+	"net.Resolver.LookupIPAddr":                               {"ctx", "host"},
+	"net.Resolver.LookupMX":                                   {"ctx", "name"},
+	"net.Resolver.LookupNS":                                   {"ctx", "name"},
+	"net.Resolver.LookupNetIP":                                {"ctx", "network", "host"},
+	"net.Resolver.LookupPort":                                 {"ctx", "network", "service"},
+	"net.Resolver.LookupSRV":                                  {"ctx", "service", "proto", "name"},
+	"net.Resolver.LookupTXT":                                  {"ctx", "name"},
+	"net.TCPConn.ReadFrom":                                    {"r"},
+	"net.TCPConn.SetKeepAlive":                                {"keepalive"},
+	"net.TCPConn.SetKeepAliveConfig":                          {"config"},
+	"net.TCPConn.SetKeepAlivePeriod":                          {"d"},
+	"net.TCPConn.SetLinger":                                   {"sec"},
+	"net.TCPConn.SetNoDelay":                                  {"noDelay"},
+	"net.TCPConn.WriteTo":                                     {"w"},
+	"net.TCPListener.SetDeadline":                             {"t"},
+	"net.UDPConn.ReadFrom":                                    {"b"},
+	"net.UDPConn.ReadFromUDP":                                 {"b"},
+	"net.UDPConn.ReadFromUDPAddrPort":                         {"b"},
+	"net.UDPConn.ReadMsgUDP":                                  {"b", "oob"},
+	"net.UDPConn.ReadMsgUDPAddrPort":                          {"b", "oob"},
+	"net.UDPConn.WriteMsgUDP":                                 {"b", "oob", "addr"},
+	"net.UDPConn.WriteMsgUDPAddrPort":                         {"b", "oob", "addr"},
+	"net.UDPConn.WriteTo":                                     {"b", "addr"},
+	"net.UDPConn.WriteToUDP":                                  {"b", "addr"},
+	"net.UDPConn.WriteToUDPAddrPort":                          {"b", "addr"},
+	"net.UnixConn.ReadFrom":                                   {"b"},
+	"net.UnixConn.ReadFromUnix":                               {"b"},
+	"net.UnixConn.ReadMsgUnix":                                {"b", "oob"},
+	"net.UnixConn.WriteMsgUnix":                               {"b", "oob", "addr"},
+	"net.UnixConn.WriteTo":                                    {"b", "addr"},
+	"net.UnixConn.WriteToUnix":                                {"b", "addr"},
+	"net.UnixListener.SetDeadline":                            {"t"},
+	"net.UnixListener.SetUnlinkOnClose":                       {"unlink"},
+	"net/http.Client.Do":                                      {"req"},
+	"net/http.Client.Get":                                     {"url"},
+	"net/http.Client.Head":                                    {"url"},
+	"net/http.Client.Post":                                    {"url", "contentType", "body"},
+	"net/http.Client.PostForm":                                {"url", "data"},
+	"net/http.ClientConn.RoundTrip":                           {"req"},
+	"net/http.ClientConn.SetStateHook":                        {"f"},
+	"net/http.CrossOriginProtection.AddInsecureBypassPattern": {"pattern"},
+	"net/http.CrossOriginProtection.AddTrustedOrigin":         {"origin"},
+	"net/http.CrossOriginProtection.Check":                    {"req"},
+	"net/http.CrossOriginProtection.Handler":                  {"h"},
+	"net/http.CrossOriginProtection.SetDenyHandler":           {"h"},
+	"net/http.Dir.Open":                                       {"name"},
+	"net/http.HandlerFunc.ServeHTTP":                          {"w", "r"},
+	"net/http.Header.Add":                                     {"key", "value"},
+	"net/http.Header.Del":                                     {"key"},
+	"net/http.Header.Get":                                     {"key"},
+	"net/http.Header.Set":                                     {"key", "value"},
+	"net/http.Header.Values":                                  {"key"},
+	"net/http.Header.Write":                                   {"w"},
+	"net/http.Header.WriteSubset":                             {"w", "exclude"},
+	"net/http.ProtocolError.Is":                               {"err"},
+	"net/http.Protocols.SetHTTP1":                             {"ok"},
+	"net/http.Protocols.SetHTTP2":                             {"ok"},
+	"net/http.Protocols.SetUnencryptedHTTP2":                  {"ok"},
+	"net/http.Request.AddCookie":                              {"c"},
+	"net/http.Request.Clone":                                  {"ctx"},
+	"net/http.Request.Cookie":                                 {"name"},
+	"net/http.Request.CookiesNamed":                           {"name"},
+	"net/http.Request.FormFile":                               {"key"},
+	"net/http.Request.FormValue":                              {"key"},
+	"net/http.Request.ParseMultipartForm":                     {"maxMemory"},
+	"net/http.Request.PathValue":                              {"name"},
+	"net/http.Request.PostFormValue":                          {"key"},
+	"net/http.Request.ProtoAtLeast":                           {"major", "minor"},
+	"net/http.Request.SetBasicAuth":                           {"username", "password"},
+	"net/http.Request.SetPathValue":                           {"name", "value"},
+	"net/http.Request.WithContext":                            {"ctx"},
+	"net/http.Request.Write":                                  {"w"},
+	"net/http.Request.WriteProxy":                             {"w"},
+	"net/http.Response.ProtoAtLeast":                          {"major", "minor"},
+	"net/http.Response.Write":                                 {"w"},
+	"net/http.ResponseController.SetReadDeadline":             {"deadline"},
+	"net/http.ResponseController.SetWriteDeadline":            {"deadline"},
+	"net/http.ServeMux.Handle":                                {"pattern", "handler"},
+	"net/http.ServeMux.HandleFunc":                            {"pattern", "handler"},
+	"net/http.ServeMux.Handler":                               {"r"},
+	"net/http.ServeMux.ServeHTTP":                             {"w", "r"},
+	"net/http.Server.ListenAndServeTLS":                       {"certFile", "keyFile"},
+	"net/http.Server.RegisterOnShutdown":                      {"f"},
+	"net/http.Server.Serve":                                   {"l"},
+	"net/http.Server.ServeTLS":                                {"l", "certFile", "keyFile"},
+	"net/http.Server.SetKeepAlivesEnabled":                    {"v"},
+	"net/http.Server.Shutdown":                                {"ctx"},
+	"net/http.Transport.CancelRequest":                        {"req"},
+	"net/http.Transport.NewClientConn":                        {"ctx", "scheme", "address"},
+	"net/http.Transport.RegisterProtocol":                     {"scheme", "rt"},
+	"net/http.Transport.RoundTrip":                            {"req"},
+	"net/url.URL.AppendBinary":                                {"b"},
+	"net/url.URL.JoinPath":                                    {"elem"},
+	"net/url.URL.Parse":                                       {"ref"},
+	"net/url.URL.ResolveReference":                            {"ref"},
+	"net/url.URL.UnmarshalBinary":                             {"text"},
+	"net/url.Values.Add":                                      {"key", "value"},
+	"net/url.Values.Del":                                      {"key"},
+	"net/url.Values.Get":                                      {"key"},
+	"net/url.Values.Has":                                      {"key"},
+	"net/url.Values.Set":                                      {"key", "value"},
+	"time.Duration.Round":                                     {"m"},
+	"time.Duration.Truncate":                                  {"m"},
+	"time.Ticker.Reset":                                       {"d"},
+	"time.Time.Add":                                           {"d"},
+	"time.Time.AddDate":                                       {"years", "months", "days"},
+	"time.Time.After":                                         {"u"},
+	"time.Time.AppendBinary":                                  {"b"},
+	"time.Time.AppendFormat":                                  {"b", "layout"},
+	"time.Time.AppendText":                                    {"b"},
+	"time.Time.Before":                                        {"u"},
+	"time.Time.Compare":                                       {"u"},
+	"time.Time.Equal":                                         {"u"},
+	"time.Time.Format":                                        {"layout"},
+	"time.Time.GobDecode":                                     {"data"},
+	"time.Time.In":                                            {"loc"},
+	"time.Time.Round":                                         {"d"},
+	"time.Time.Sub":                                           {"u"},
+	"time.Time.Truncate":                                      {"d"},
+	"time.Time.UnmarshalBinary":                               {"data"},
+	"time.Time.UnmarshalJSON":                                 {"data"},
+	"time.Time.UnmarshalText":                                 {"data"},
+	"time.Timer.Reset":                                        {"d"},
+}
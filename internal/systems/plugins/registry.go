@@ -0,0 +1,401 @@
+package plugins
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/logger/log"
+)
+
+const (
+	// registryTimeout is the maximum amount of time a request to the plugin registry may take
+	registryTimeout = time.Minute
+	// maxPluginSize is the maximum size in bytes of a plugin downloaded from the registry
+	maxPluginSize = 50 << 20
+)
+
+var (
+	ownersMtx = sync.Mutex{}
+	// owners contains the IDs of the users that own the bot's application
+	owners map[string]bool
+)
+
+// pluginNameRegex matches plugin names that are safe to use as file names
+var pluginNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// registryIndex is the index served by a plugin registry
+type registryIndex struct {
+	Plugins []RegistryEntry `json:"plugins"`
+}
+
+// RegistryEntry describes a plugin available in a registry. The URL may be
+// relative to the index, and points to either a JavaScript file or a zip
+// archive containing a multi-file plugin. The signature is the base64-encoded
+// ed25519 signature of the entry's signed message (see [RegistryEntry.SignedMessage]).
+type RegistryEntry struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	URL       string `json:"url"`
+	Signature string `json:"signature"`
+}
+
+// SignedMessage returns the message that the entry's signature is made over,
+// given the contents of the file at its URL. It contains the plugin's name and
+// version along with the SHA-256 hash of the file, so that a signed file can't be
+// served as a different plugin or version than the one it was signed for:
+//
+//	owobot-plugin
+//	<name>
+//	<version>
+//	<hex-encoded sha256 of the file>
+func (e RegistryEntry) SignedMessage(data []byte) []byte {
+	hash := sha256.Sum256(data)
+	return []byte("owobot-plugin\n" + e.Name + "\n" + e.Version + "\n" + hex.EncodeToString(hash[:]))
+}
+
+// Install downloads the plugin with the given name from the registry configured
+// in config, verifies its signature, and stores it in dir. It returns the path
+// the plugin was stored at. The plugin isn't loaded.
+func Install(dir string, config Config, name string) (string, RegistryEntry, error) {
+	if !pluginNameRegex.MatchString(name) {
+		return "", RegistryEntry{}, fmt.Errorf("invalid plugin name: %q", name)
+	}
+
+	if config.RegistryURL == "" {
+		return "", RegistryEntry{}, errors.New("no plugin registry is configured")
+	}
+
+	keys, err := trustedKeys(config.RegistryKeys)
+	if err != nil {
+		return "", RegistryEntry{}, err
+	} else if len(keys) == 0 {
+		return "", RegistryEntry{}, errors.New("no trusted registry keys are configured")
+	}
+
+	indexURL, err := url.Parse(config.RegistryURL)
+	if err != nil {
+		return "", RegistryEntry{}, fmt.Errorf("registry url: %w", err)
+	}
+
+	var index registryIndex
+	data, err := registryGet(indexURL)
+	if err != nil {
+		return "", RegistryEntry{}, fmt.Errorf("registry index: %w", err)
+	}
+
+	err = json.Unmarshal(data, &index)
+	if err != nil {
+		return "", RegistryEntry{}, fmt.Errorf("registry index: %w", err)
+	}
+
+	var entry RegistryEntry
+	for _, e := range index.Plugins {
+		if e.Name == name {
+			entry = e
+			break
+		}
+	}
+	if entry.Name == "" {
+		return "", RegistryEntry{}, fmt.Errorf("plugin %q not found in registry", name)
+	}
+
+	pluginURL, err := indexURL.Parse(entry.URL)
+	if err != nil {
+		return "", entry, fmt.Errorf("plugin url: %w", err)
+	}
+
+	data, err = registryGet(pluginURL)
+	if err != nil {
+		return "", entry, fmt.Errorf("plugin download: %w", err)
+	}
+
+	err = verifySignature(keys, entry.SignedMessage(data), entry.Signature)
+	if err != nil {
+		return "", entry, err
+	}
+
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return "", entry, err
+	}
+
+	if path.Ext(pluginURL.Path) == ".zip" {
+		pluginPath := filepath.Join(dir, name)
+		return pluginPath, entry, writePluginArchive(pluginPath, data)
+	}
+
+	pluginPath := filepath.Join(dir, name+".js")
+	return pluginPath, entry, writePluginFile(pluginPath, data)
+}
+
+// trustedKeys decodes the base64-encoded ed25519 public keys in the configuration
+func trustedKeys(encoded []string) ([]ed25519.PublicKey, error) {
+	out := make([]ed25519.PublicKey, 0, len(encoded))
+	for _, key := range encoded {
+		data, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("registry key %q: %w", key, err)
+		} else if len(data) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("registry key %q: invalid ed25519 public key", key)
+		}
+		out = append(out, ed25519.PublicKey(data))
+	}
+	return out, nil
+}
+
+// verifySignature checks that the base64-encoded signature of msg was made by one of the trusted keys
+func verifySignature(keys []ed25519.PublicKey, msg []byte, signature string) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("plugin signature: %w", err)
+	}
+
+	for _, key := range keys {
+		if ed25519.Verify(key, msg, sig) {
+			return nil
+		}
+	}
+
+	return errors.New("plugin signature wasn't made by a trusted key")
+}
+
+// registryGet downloads the file at the given URL. Besides HTTP(S),
+// file URLs are supported so that a local directory can act as a registry.
+func registryGet(u *url.URL) ([]byte, error) {
+	transport := http.DefaultTransport
+	switch u.Scheme {
+	case "http", "https":
+	case "file":
+		transport = http.NewFileTransport(http.Dir("/"))
+	default:
+		return nil, fmt.Errorf("unsupported url scheme: %q", u.Scheme)
+	}
+
+	client := &http.Client{Transport: transport, Timeout: registryTimeout}
+	res, err := client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", u, res.Status)
+	}
+
+	// Read one byte past the limit so we can tell if the file was too large
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxPluginSize+1))
+	if err != nil {
+		return nil, err
+	} else if len(data) > maxPluginSize {
+		return nil, fmt.Errorf("%s: larger than %d bytes", u, maxPluginSize)
+	}
+
+	return data, nil
+}
+
+// writePluginFile atomically writes a single-file plugin to the given path
+func writePluginFile(pluginPath string, data []byte) error {
+	if isPluginDir(pluginPath) {
+		return fmt.Errorf("%s is a multi-file plugin", pluginPath)
+	}
+
+	// The temporary file is hidden, so it's never loaded as a plugin
+	tmpPath := hiddenPath(pluginPath)
+	err := os.WriteFile(tmpPath, data, 0o644)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpPath, pluginPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+// writePluginArchive extracts a multi-file plugin from a zip archive into
+// the given directory, replacing the directory's contents if it already exists.
+func writePluginArchive(pluginPath string, data []byte) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("plugin archive: %w", err)
+	}
+
+	// The archive is extracted into a hidden temporary directory first, so
+	// that the plugin is never loaded while it's only partially extracted.
+	tmpPath := hiddenPath(pluginPath)
+	err = os.RemoveAll(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	err = extractZip(zr, tmpPath)
+	if err != nil {
+		os.RemoveAll(tmpPath)
+		return fmt.Errorf("plugin archive: %w", err)
+	}
+
+	if !isPluginDir(tmpPath) {
+		os.RemoveAll(tmpPath)
+		return fmt.Errorf("plugin archive doesn't contain a %s file", manifestName)
+	}
+
+	err = os.RemoveAll(pluginPath)
+	if err != nil {
+		os.RemoveAll(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, pluginPath)
+}
+
+// hiddenPath returns a hidden temporary path next to the given path,
+// which is skipped when looking for plugins to load.
+func hiddenPath(pluginPath string) string {
+	return filepath.Join(filepath.Dir(pluginPath), "."+filepath.Base(pluginPath)+".tmp")
+}
+
+// extractZip extracts the files in a zip archive into dir
+func extractZip(zr *zip.Reader, dir string) error {
+	var total uint64
+	for _, f := range zr.File {
+		// Make sure the archive can't write outside of the directory
+		if !filepath.IsLocal(f.Name) {
+			return fmt.Errorf("invalid file path: %q", f.Name)
+		}
+
+		target := filepath.Join(dir, f.Name)
+		if f.FileInfo().IsDir() || strings.HasSuffix(f.Name, "/") {
+			err := os.MkdirAll(target, 0o755)
+			if err != nil {
+				return err
+			}
+			continue
+		} else if !f.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", f.Name)
+		}
+
+		total += f.UncompressedSize64
+		if total > maxPluginSize {
+			return fmt.Errorf("larger than %d bytes when extracted", maxPluginSize)
+		}
+
+		err := extractZipFile(f, target)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractZipFile writes a single file from a zip archive to target
+func extractZipFile(f *zip.File, target string) error {
+	err := os.MkdirAll(filepath.Dir(target), 0o755)
+	if err != nil {
+		return err
+	}
+
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	fl, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer fl.Close()
+
+	// The declared size can't be trusted, so the copy is limited as well
+	_, err = io.Copy(fl, io.LimitReader(r, int64(f.UncompressedSize64)))
+	return err
+}
+
+// installPlugin installs the plugin with the given name from the registry
+// and loads it, reloading it if it was already loaded.
+func installPlugin(sess *discordgo.Session, name string) (*Plugin, error) {
+	if existing, ok := findPlugin(name); ok && !isRegistryPath(existing.path, name) {
+		return nil, fmt.Errorf("plugin %q is already installed at %s", name, existing.path)
+	}
+
+	pluginPath, entry, err := Install(pluginDir, cfg, name)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Plugin installed from registry").Str("plugin", entry.Name).Str("version", entry.Version).Send()
+
+	reloadMtx.Lock()
+	defer reloadMtx.Unlock()
+
+	if plugin, ok := pluginByPath(pluginPath); ok {
+		return reloadLocked(plugin, sess)
+	}
+
+	plugin, err := loadAndInitPlugin(pluginPath, sess)
+	if err != nil {
+		return nil, err
+	} else if plugin == nil {
+		return nil, fmt.Errorf("%s: plugin info not provided", name)
+	}
+
+	revokeGrownCapabilities(sess, plugin)
+	addPlugin(plugin)
+	syncPluginGuilds(sess, plugin.Info.Name)
+	log.Info("Plugin loaded").Str("plugin", plugin.Info.Name).Str("version", plugin.Info.Version).Send()
+	return plugin, nil
+}
+
+// isRegistryPath checks whether path is where a plugin with
+// the given name would be installed from the registry.
+func isRegistryPath(pluginPath, name string) bool {
+	return absPath(pluginPath) == absPath(filepath.Join(pluginDir, name+".js")) ||
+		absPath(pluginPath) == absPath(filepath.Join(pluginDir, name))
+}
+
+// isBotOwner checks whether the given user owns the bot's application,
+// either directly or as a member of the team that owns it.
+func isBotOwner(s *discordgo.Session, userID string) (bool, error) {
+	ownersMtx.Lock()
+	defer ownersMtx.Unlock()
+
+	if owners == nil {
+		app, err := s.Application("@me")
+		if err != nil {
+			return false, err
+		}
+
+		owners = map[string]bool{}
+		if app.Owner != nil {
+			owners[app.Owner.ID] = true
+		}
+		if app.Team != nil {
+			for _, member := range app.Team.Members {
+				owners[member.User.ID] = true
+			}
+		}
+	}
+
+	return owners[userID], nil
+}
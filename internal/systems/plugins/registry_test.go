@@ -0,0 +1,76 @@
+package plugins
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestRegistry serves a registry index with the given entries, and a plugin file for each of them
+func newTestRegistry(t *testing.T, entries []RegistryEntry, files map[string][]byte) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(registryIndex{Plugins: entries})
+	})
+	for name, data := range files {
+		data := data
+		mux.HandleFunc("/"+name, func(w http.ResponseWriter, r *http.Request) {
+			w.Write(data)
+		})
+	}
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestInstallSignedManifest(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte(`owobot.pluginInfo = {name: "foo", version: "2", desc: "d"}`)
+	sign := func(e RegistryEntry) RegistryEntry {
+		e.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, e.SignedMessage(data)))
+		return e
+	}
+
+	foo := sign(RegistryEntry{Name: "foo", Version: "2", URL: "foo.js"})
+	entries := []RegistryEntry{
+		foo,
+		// The file signed for foo, served as a different plugin
+		{Name: "bar", Version: "2", URL: "foo.js", Signature: foo.Signature},
+		// The file signed for foo, served as a different version
+		{Name: "baz", Version: "1", URL: "foo.js", Signature: sign(RegistryEntry{Name: "baz", Version: "2"}).Signature},
+	}
+	srv := newTestRegistry(t, entries, map[string][]byte{"foo.js": data})
+
+	config := Config{
+		RegistryURL:  srv.URL + "/index.json",
+		RegistryKeys: []string{base64.StdEncoding.EncodeToString(pub)},
+	}
+
+	dir := t.TempDir()
+	pluginPath, _, err := Install(dir, config, "foo")
+	if err != nil {
+		t.Fatalf("installing a correctly signed plugin failed: %s", err)
+	}
+	if _, err := os.Stat(pluginPath); err != nil {
+		t.Errorf("installed plugin wasn't written: %s", err)
+	}
+
+	for _, name := range []string{"bar", "baz"} {
+		_, _, err = Install(dir, config, name)
+		if err == nil {
+			t.Errorf("installing %s with a signature made for another entry succeeded", name)
+		}
+		if _, err := os.Stat(filepath.Join(dir, name+".js")); err == nil {
+			t.Errorf("%s was written even though its signature is invalid", name)
+		}
+	}
+}
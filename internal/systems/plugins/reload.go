@@ -0,0 +1,173 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dop251/goja"
+	"go.elara.ws/logger/log"
+	"go.elara.ws/owobot/internal/db"
+)
+
+// reloadMtx makes sure only one reload happens at a time, since
+// both the watcher and the reload command can trigger them.
+var reloadMtx = sync.Mutex{}
+
+// unload calls the plugin's onUnload hook, stops its event loop, closes
+// its prepared statements, and removes it along with all of its event handlers.
+func unload(plugin *Plugin) {
+	if plugin.api.OnUnload != nil {
+		callable, ok := goja.AssertFunction(plugin.api.OnUnload)
+		if !ok {
+			log.Warn("OnUnload value is not callable, ignoring.").Str("plugin", plugin.Info.Name).Send()
+		} else {
+			err := <-callOnLoop(plugin.api, func(vm *goja.Runtime) error {
+				_, err := callable(vm.ToValue(plugin.api))
+				return err
+			})
+			if err != nil {
+				log.Warn("Error in plugin onUnload").Str("plugin", plugin.Info.Name).Err(err).Send()
+			}
+		}
+	}
+
+	stopLoop(plugin.api)
+	plugin.release()
+	removeHandlers(plugin.api)
+	removePlugin(plugin)
+}
+
+// reload loads the given plugin again from its files and replaces it with
+// the new version. If the new version can't be loaded, the old one keeps running.
+func reload(plugin *Plugin, sess *discordgo.Session) (*Plugin, error) {
+	reloadMtx.Lock()
+	defer reloadMtx.Unlock()
+	return reloadLocked(plugin, sess)
+}
+
+// reloadLocked is the same as reload, but it expects reloadMtx to already be locked.
+func reloadLocked(plugin *Plugin, sess *discordgo.Session) (*Plugin, error) {
+	// The new version is initialized before the old one is unloaded, so its
+	// init function runs before the old version's onUnload hook. Its event
+	// handlers aren't called until it's added to the plugin list.
+	newPlugin, err := loadAndInitPlugin(plugin.path, sess)
+	if err == nil && newPlugin == nil {
+		err = errors.New("plugin info is no longer provided")
+	}
+	if err != nil {
+		// Loading the new version records it in the database, so the old
+		// version is recorded again since it's the one that's still running.
+		if dberr := db.AddPlugin(plugin.Info); dberr != nil {
+			log.Warn("Error restoring plugin info").Str("plugin", plugin.Info.Name).Err(dberr).Send()
+		}
+		return nil, fmt.Errorf("%s: %w", plugin.Info.Name, err)
+	}
+
+	unload(plugin)
+	revokeGrownCapabilities(sess, newPlugin)
+	addPlugin(newPlugin)
+	syncPluginGuilds(sess, plugin.Info.Name, newPlugin.Info.Name)
+	log.Info("Plugin reloaded").Str("plugin", newPlugin.Info.Name).Str("version", newPlugin.Info.Version).Send()
+	return newPlugin, nil
+}
+
+// pluginByPath returns the plugin that was loaded from the given path
+func pluginByPath(path string) (*Plugin, bool) {
+	for _, plugin := range allPlugins() {
+		if plugin.path == path {
+			return plugin, true
+		}
+	}
+	return nil, false
+}
+
+// watch polls the plugin directory for changes. Plugins whose files were modified
+// are reloaded, new plugins are loaded, and plugins whose files were removed are
+// unloaded. If anything in the shared library directory changes, all plugins
+// are reloaded, since any of them might use it.
+func watch(dir string, sess *discordgo.Session) {
+	modTimes, err := scanDir(dir)
+	if err != nil {
+		log.Error("Error scanning plugin directory, not watching for changes").Err(err).Send()
+		return
+	}
+
+	libModTime, err := latestModTime(cfg.LibDir)
+	if err != nil {
+		log.Warn("Error scanning plugin library directory").Err(err).Send()
+	}
+
+	for range time.Tick(time.Duration(cfg.WatchInterval)) {
+		current, err := scanDir(dir)
+		if err != nil {
+			log.Warn("Error scanning plugin directory").Err(err).Send()
+			continue
+		}
+
+		currentLibModTime, err := latestModTime(cfg.LibDir)
+		if err != nil {
+			log.Warn("Error scanning plugin library directory").Err(err).Send()
+			currentLibModTime = libModTime
+		}
+		libChanged := !currentLibModTime.Equal(libModTime)
+
+		reloadMtx.Lock()
+		for path, modTime := range current {
+			if prev, ok := modTimes[path]; ok && prev.Equal(modTime) && !libChanged {
+				continue
+			}
+
+			if plugin, ok := pluginByPath(path); ok {
+				_, err = reloadLocked(plugin, sess)
+			} else {
+				var plugin *Plugin
+				plugin, err = loadAndInitPlugin(path, sess)
+				if plugin != nil {
+					revokeGrownCapabilities(sess, plugin)
+					addPlugin(plugin)
+					syncPluginGuilds(sess, plugin.Info.Name)
+					log.Info("Plugin loaded").Str("plugin", plugin.Info.Name).Str("version", plugin.Info.Version).Send()
+				}
+			}
+			if err != nil {
+				log.Error("Error loading changed plugin").Str("path", path).Err(err).Send()
+			}
+		}
+
+		for path := range modTimes {
+			if _, ok := current[path]; ok {
+				continue
+			}
+
+			if plugin, ok := pluginByPath(path); ok {
+				unload(plugin)
+				syncPluginGuilds(sess, plugin.Info.Name)
+				log.Info("Plugin unloaded").Str("plugin", plugin.Info.Name).Send()
+			}
+		}
+		reloadMtx.Unlock()
+
+		modTimes = current
+		libModTime = currentLibModTime
+	}
+}
+
+// scanDir returns the latest modification times of all the plugins in dir
+func scanDir(dir string) (map[string]time.Time, error) {
+	paths, err := pluginPaths(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		out[path], err = latestModTime(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,84 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/owobot/internal/db"
+)
+
+func TestReloadKeepsOldVersionOnFailure(t *testing.T) {
+	err := db.Init(context.Background(), "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "reloaded.js")
+	writePlugin := func(version, init string) {
+		src := `owobot.pluginInfo = {name: "reloaded", version: "` + version + `", desc: "d"}
+owobot.init = function() {` + init + `}`
+		err := os.WriteFile(path, []byte(src), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writePlugin("1", "")
+	err = Load(dir, Config{}, &discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Start(&discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, plugin := range allPlugins() {
+			unload(plugin)
+		}
+	})
+
+	old, ok := findPlugin("reloaded")
+	if !ok {
+		t.Fatal("plugin wasn't loaded")
+	}
+
+	writePlugin("2", `throw new Error("broken")`)
+	_, err = reload(old, &discordgo.Session{})
+	if err == nil {
+		t.Fatal("reloading a plugin whose init function throws succeeded")
+	}
+
+	if plugin, ok := findPlugin("reloaded"); !ok || plugin != old {
+		t.Fatal("old version was unloaded even though the new one failed")
+	} else if !old.api.active.Load() {
+		t.Error("old version's handlers were deactivated")
+	}
+
+	info, err := db.GetPlugin("reloaded")
+	if err != nil {
+		t.Fatal(err)
+	} else if info.Version != "1" {
+		t.Errorf("expected version 1 to be recorded after the failed reload, got %s", info.Version)
+	}
+
+	writePlugin("3", "")
+	newPlugin, err := reload(old, &discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plugin, ok := findPlugin("reloaded"); !ok || plugin != newPlugin {
+		t.Error("new version wasn't added to the plugin list")
+	} else if old.api.active.Load() {
+		t.Error("old version is still active after the reload")
+	} else if len(allPlugins()) != 1 {
+		t.Errorf("expected only the new version to be loaded, got %d plugins", len(allPlugins()))
+	}
+}
@@ -0,0 +1,317 @@
+package plugins
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/robfig/cron/v3"
+	"go.elara.ws/logger/log"
+	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/shards"
+)
+
+const (
+	// schedulerInterval is how often due timers and schedules are checked for
+	schedulerInterval = time.Second
+	// orphanedTimerTTL is how long after it was due a timer that can't run is kept.
+	// A plugin that's being reloaded or fixed gets its timers back once it's loaded,
+	// but the timers of plugins that were removed are eventually pruned, so that
+	// they aren't selected as due forever.
+	orphanedTimerTTL = 24 * time.Hour
+)
+
+// cronParser parses standard five-field cron expressions, as well as descriptors such as "@daily"
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// schedule represents a plugin handler that runs according to a cron expression
+type schedule struct {
+	name string
+	spec cron.Schedule
+	fn   goja.Callable
+}
+
+// scheduleKey identifies a schedule in a specific guild
+type scheduleKey struct {
+	plugin  string
+	guildID string
+	name    string
+}
+
+var (
+	lastRunsMtx = sync.Mutex{}
+	// lastRuns caches the last time each schedule ran in each guild
+	lastRuns = map[scheduleKey]time.Time{}
+)
+
+// Schedule adds a handler that's called in every guild where the plugin is enabled,
+// at the times described by the given cron expression. The last run in each guild is
+// stored in the database, so a run that was missed while owobot wasn't running happens
+// as soon as it starts again.
+func (oa *owobotAPI) Schedule(name, expr string, fn goja.Value) error {
+	callable, ok := goja.AssertFunction(fn)
+	if !ok {
+		return errors.New("value passed to schedule is not a function")
+	}
+
+	spec, err := cronParser.Parse(expr)
+	if err != nil {
+		return fmt.Errorf("schedule %q: %w", name, err)
+	}
+
+	oa.mu.Lock()
+	defer oa.mu.Unlock()
+
+	for _, s := range oa.schedules {
+		if s.name == name {
+			return fmt.Errorf("schedule %q already exists", name)
+		}
+	}
+
+	oa.schedules = append(oa.schedules, schedule{name: name, spec: spec, fn: callable})
+	return nil
+}
+
+// OnTimer adds a handler for the timers with the given name started by [owobotAPI.RunAt]
+func (oa *owobotAPI) OnTimer(name string, fn goja.Value) error {
+	callable, ok := goja.AssertFunction(fn)
+	if !ok {
+		return errors.New("value passed to onTimer is not a function")
+	}
+
+	oa.mu.Lock()
+	defer oa.mu.Unlock()
+
+	if oa.timerHandlers == nil {
+		oa.timerHandlers = map[string]goja.Callable{}
+	}
+
+	if _, ok := oa.timerHandlers[name]; ok {
+		return fmt.Errorf("timer handler %q already exists", name)
+	}
+
+	oa.timerHandlers[name] = callable
+	return nil
+}
+
+// RunAt starts a timer that calls the handler for name with the given payload
+// at the given time, which may be a Date, a unix timestamp in milliseconds, or
+// an RFC 3339 string. Timers are stored in the database, so they survive restarts.
+// The payload must contain a guildID, and the timer is cancelled if the plugin is
+// disabled in that guild. The timer's ID is returned.
+func (oa *owobotAPI) RunAt(name string, at goja.Value, payload goja.Value) (int64, error) {
+	runAt, err := exportTime(at)
+	if err != nil {
+		return 0, err
+	}
+
+	guildID, data, err := oa.guildPayload(payload)
+	if err != nil {
+		return 0, fmt.Errorf("timer %w", err)
+	}
+
+	return db.AddPluginTimer(oa.PluginInfo.Name, guildID, name, runAt, string(data))
+}
+
+// CancelTimer cancels the timer with the given ID, returning whether it existed
+func (oa *owobotAPI) CancelTimer(id int64) (bool, error) {
+	return db.DeletePluginTimer(oa.PluginInfo.Name, id)
+}
+
+// timerHandler returns the handler for the timers with the given name
+func (oa *owobotAPI) timerHandler(name string) (goja.Callable, bool) {
+	oa.mu.Lock()
+	defer oa.mu.Unlock()
+	fn, ok := oa.timerHandlers[name]
+	return fn, ok
+}
+
+// allSchedules returns a snapshot of the plugin's schedules
+func (oa *owobotAPI) allSchedules() []schedule {
+	oa.mu.Lock()
+	defer oa.mu.Unlock()
+	return append([]schedule(nil), oa.schedules...)
+}
+
+// exportTime converts a JavaScript Date, millisecond timestamp, or RFC 3339 string into a time
+func exportTime(v goja.Value) (time.Time, error) {
+	if v == nil {
+		return time.Time{}, errors.New("no time provided")
+	}
+
+	switch v := v.Export().(type) {
+	case time.Time:
+		return v, nil
+	case int64:
+		return time.UnixMilli(v), nil
+	case float64:
+		return time.UnixMilli(int64(v)), nil
+	case string:
+		return time.Parse(time.RFC3339, v)
+	default:
+		return time.Time{}, fmt.Errorf("invalid time: %v", v)
+	}
+}
+
+// runScheduler runs plugin timers and schedules when they're due
+func runScheduler() {
+	for now := range time.Tick(schedulerInterval) {
+		runTimers(now)
+		runSchedules(now)
+	}
+}
+
+// runTimers runs all the plugin timers that are due
+func runTimers(now time.Time) {
+	timers, err := db.DuePluginTimers(now)
+	if err != nil {
+		log.Warn("Error getting due plugin timers").Err(err).Send()
+		return
+	}
+
+	for _, timer := range timers {
+		// Timers for guilds on shards run by other processes are left for them
+		if !shards.Local(timer.GuildID) {
+			continue
+		}
+
+		expired := now.Sub(time.Unix(timer.RunAt, 0)) > orphanedTimerTTL
+
+		plugin, ok := findPlugin(timer.Plugin)
+		if !ok {
+			// The plugin may not be loaded yet, so keep the timer until it is
+			if expired {
+				pruneTimer(timer, "plugin isn't installed")
+			}
+			continue
+		}
+
+		// The handler is looked up before the timer is removed,
+		// so that a timer without a handler isn't lost.
+		fn, ok := plugin.api.timerHandler(timer.Name)
+		if !ok {
+			if expired {
+				pruneTimer(timer, "plugin has no handler for it")
+			}
+			continue
+		}
+
+		// Timers are removed before they run, so that a timer that
+		// fails or takes a long time never runs more than once.
+		deleted, err := db.DeletePluginTimer(timer.Plugin, timer.ID)
+		if err != nil {
+			log.Warn("Error removing plugin timer").Str("plugin", timer.Plugin).Err(err).Send()
+			continue
+		} else if !deleted || !pluginEnabled(timer.GuildID, timer.Plugin) {
+			continue
+		}
+
+		go func(timer db.PluginTimer) {
+			err := <-invoke(plugin.api, timer.GuildID, "timer "+timer.Name, func(vm *goja.Runtime) error {
+				var payload any
+				err := json.Unmarshal([]byte(timer.Payload), &payload)
+				if err != nil {
+					return err
+				}
+				sess := shards.ForGuild(timer.GuildID, plugin.api.sess)
+				_, err = fn(vm.ToValue(plugin.api), vm.ToValue(plugin.api.session(sess)), vm.ToValue(payload))
+				return err
+			})
+			if err != nil {
+				log.Warn("Error running plugin timer").Str("plugin", timer.Plugin).Str("name", timer.Name).Err(err).Send()
+			}
+		}(timer)
+	}
+}
+
+// pruneTimer removes a timer that can't run
+func pruneTimer(timer db.PluginTimer, reason string) {
+	_, err := db.DeletePluginTimer(timer.Plugin, timer.ID)
+	if err != nil {
+		log.Warn("Error removing plugin timer").Str("plugin", timer.Plugin).Err(err).Send()
+		return
+	}
+	log.Warn("Removed plugin timer that couldn't run").
+		Str("plugin", timer.Plugin).
+		Str("name", timer.Name).
+		Str("reason", reason).
+		Send()
+}
+
+// runSchedules runs all the plugin schedules that are due in every guild
+func runSchedules(now time.Time) {
+	for _, plugin := range allPlugins() {
+		for _, sched := range plugin.api.allSchedules() {
+			for _, guildID := range guildsWithPlugins(plugin.Info.Name) {
+				if !shards.Local(guildID) {
+					continue
+				}
+
+				key := scheduleKey{plugin: plugin.Info.Name, guildID: guildID, name: sched.name}
+				due, err := scheduleDue(key, sched, now)
+				if err != nil {
+					log.Warn("Error checking plugin schedule").Str("plugin", key.plugin).Str("name", key.name).Err(err).Send()
+					continue
+				} else if !due {
+					continue
+				}
+
+				go func(plugin *Plugin, sched schedule, guildID string) {
+					err := <-invoke(plugin.api, guildID, "schedule "+sched.name, func(vm *goja.Runtime) error {
+						sess := shards.ForGuild(guildID, plugin.api.sess)
+						_, err := sched.fn(vm.ToValue(plugin.api), vm.ToValue(plugin.api.session(sess)), vm.ToValue(guildID))
+						return err
+					})
+					if err != nil {
+						log.Warn("Error running plugin schedule").Str("plugin", plugin.Info.Name).Str("name", sched.name).Err(err).Send()
+					}
+				}(plugin, sched, guildID)
+			}
+		}
+	}
+}
+
+// scheduleDue checks whether a schedule is due in a guild. If it is, its last
+// run is set to now. A schedule that has never run before starts counting from now.
+func scheduleDue(key scheduleKey, sched schedule, now time.Time) (bool, error) {
+	lastRunsMtx.Lock()
+	defer lastRunsMtx.Unlock()
+
+	lastRun, ok := lastRuns[key]
+	if !ok {
+		var err error
+		lastRun, err = db.PluginScheduleLastRun(key.plugin, key.guildID, key.name)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	due := !lastRun.IsZero() && !sched.spec.Next(lastRun).After(now)
+	if lastRun.IsZero() || due {
+		err := db.SetPluginScheduleLastRun(key.plugin, key.guildID, key.name, now)
+		if err != nil {
+			return false, err
+		}
+		lastRun = now
+	}
+
+	lastRuns[key] = lastRun
+	return due, nil
+}
+
+// cancelSchedules cancels all of a plugin's timers and schedules in a guild
+func cancelSchedules(guildID, pluginName string) error {
+	lastRunsMtx.Lock()
+	defer lastRunsMtx.Unlock()
+
+	for key := range lastRuns {
+		if key.plugin == pluginName && key.guildID == guildID {
+			delete(lastRuns, key)
+		}
+	}
+
+	return db.DeletePluginSchedules(pluginName, guildID)
+}
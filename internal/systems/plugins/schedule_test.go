@@ -0,0 +1,45 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.elara.ws/owobot/internal/db"
+)
+
+func TestRunTimersPrunesOrphanedTimers(t *testing.T) {
+	err := db.Init(context.Background(), "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now()
+	oldID, err := db.AddPluginTimer("removed", "1", "remind", now.Add(-2*orphanedTimerTTL), "null")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recentID, err := db.AddPluginTimer("removed", "1", "remind", now.Add(-time.Minute), "null")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runTimers(now)
+
+	timers, err := db.DuePluginTimers(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := map[int64]bool{}
+	for _, timer := range timers {
+		ids[timer.ID] = true
+	}
+	if ids[oldID] {
+		t.Error("expired timer of a plugin that isn't installed wasn't pruned")
+	}
+	if !ids[recentID] {
+		t.Error("recent timer of a plugin that isn't loaded was removed")
+	}
+}
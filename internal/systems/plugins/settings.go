@@ -0,0 +1,240 @@
+package plugins
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dop251/goja"
+	"go.elara.ws/logger/log"
+	"go.elara.ws/owobot/internal/db"
+)
+
+// validateSettings checks that a plugin's settings schema is valid
+func validateSettings(settings []db.PluginSetting) error {
+	seen := map[string]bool{}
+	for _, setting := range settings {
+		if setting.Name == "" {
+			return errors.New("setting names must not be empty")
+		} else if seen[setting.Name] {
+			return fmt.Errorf("setting %q is declared more than once", setting.Name)
+		}
+		seen[setting.Name] = true
+
+		switch setting.Type {
+		case db.SettingChannel, db.SettingRole, db.SettingString, db.SettingInt, db.SettingBool:
+		default:
+			return fmt.Errorf("setting %q has unknown type %q", setting.Name, setting.Type)
+		}
+
+		if setting.Default != nil {
+			if _, err := convertSetting(setting, setting.Default); err != nil {
+				return fmt.Errorf("setting %q has an invalid default value: %w", setting.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// findSetting finds the setting with the given name in a plugin's schema
+func findSetting(info db.PluginInfo, name string) (db.PluginSetting, bool) {
+	for _, setting := range info.Settings {
+		if setting.Name == name {
+			return setting, true
+		}
+	}
+	return db.PluginSetting{}, false
+}
+
+// convertSetting converts a value into the Go type used for the given setting.
+// Channels, roles, and strings are strings, ints are int64, and bools are bool.
+func convertSetting(setting db.PluginSetting, v any) (any, error) {
+	switch setting.Type {
+	case db.SettingChannel, db.SettingRole, db.SettingString:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case db.SettingInt:
+		switch n := v.(type) {
+		case int64:
+			return n, nil
+		case float64:
+			if n == float64(int64(n)) {
+				return int64(n), nil
+			}
+		}
+	case db.SettingBool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("expected %s value, got %T", setting.Type, v)
+}
+
+// parseSetting parses user input into a value for the given setting,
+// making sure any channels or roles exist in the guild.
+func parseSetting(s *discordgo.Session, guildID string, setting db.PluginSetting, input string) (any, error) {
+	input = strings.TrimSpace(input)
+	switch setting.Type {
+	case db.SettingChannel:
+		id := strings.TrimSuffix(strings.TrimPrefix(input, "<#"), ">")
+		channel, err := s.State.Channel(id)
+		if err != nil {
+			channel, err = s.Channel(id)
+			if err != nil {
+				return nil, fmt.Errorf("no such channel: %q", input)
+			}
+		}
+		if channel.GuildID != guildID {
+			return nil, fmt.Errorf("channel %q is not in this guild", input)
+		}
+		return channel.ID, nil
+	case db.SettingRole:
+		id := strings.TrimSuffix(strings.TrimPrefix(input, "<@&"), ">")
+		if _, err := s.State.Role(guildID, id); err == nil {
+			return id, nil
+		}
+		roles, err := s.GuildRoles(guildID)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range roles {
+			if role.ID == id {
+				return id, nil
+			}
+		}
+		return nil, fmt.Errorf("no such role: %q", input)
+	case db.SettingInt:
+		n, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer: %q", input)
+		}
+		return n, nil
+	case db.SettingBool:
+		b, err := strconv.ParseBool(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean: %q", input)
+		}
+		return b, nil
+	default:
+		return input, nil
+	}
+}
+
+// formatSetting formats a setting value for display in discord
+func formatSetting(setting db.PluginSetting, v any) string {
+	if v == nil {
+		return "*not set*"
+	}
+
+	switch setting.Type {
+	case db.SettingChannel:
+		return fmt.Sprintf("<#%s>", v)
+	case db.SettingRole:
+		return fmt.Sprintf("<@&%s>", v)
+	default:
+		return fmt.Sprintf("`%v`", v)
+	}
+}
+
+// guildConfig returns the values of all of a plugin's settings in the given
+// guild. Settings that haven't been configured have their default values.
+func guildConfig(info db.PluginInfo, guildID string) (map[string]any, error) {
+	stored, err := db.PluginConfig(info.Name, guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]any, len(info.Settings))
+	for _, setting := range info.Settings {
+		out[setting.Name], _ = convertSetting(setting, setting.Default)
+
+		raw, ok := stored[setting.Name]
+		if !ok {
+			continue
+		}
+
+		var v any
+		err = json.Unmarshal([]byte(raw), &v)
+		if err == nil {
+			v, err = convertSetting(setting, v)
+		}
+		if err != nil {
+			// This can happen if a plugin changes the type of one of its settings
+			log.Warn("Ignoring invalid plugin setting value").
+				Str("plugin", info.Name).
+				Str("setting", setting.Name).
+				Err(err).
+				Send()
+			continue
+		}
+		out[setting.Name] = v
+	}
+	return out, nil
+}
+
+// setConfig sets the value of a plugin setting in the given guild and calls
+// the plugin's onConfigChange hook. A nil value resets the setting to its default.
+// The plugin must be enabled in the guild.
+func setConfig(guildID string, plugin *Plugin, setting db.PluginSetting, value any) error {
+	if !pluginEnabled(guildID, plugin.Info.Name) {
+		return fmt.Errorf("plugin %q is not enabled in this guild", plugin.Info.Name)
+	}
+
+	prev, err := guildConfig(plugin.Info, guildID)
+	if err != nil {
+		return err
+	}
+
+	value, err = storeConfig(guildID, plugin.Info, setting, value)
+	if err != nil {
+		return err
+	}
+
+	if plugin.api.OnConfigChange != nil {
+		callable, ok := goja.AssertFunction(plugin.api.OnConfigChange)
+		if !ok {
+			return fmt.Errorf("onConfigChange value is not callable")
+		}
+
+		err = <-callOnLoop(plugin.api, func(vm *goja.Runtime) error {
+			_, err := callable(
+				vm.ToValue(plugin.api),
+				vm.ToValue(guildID),
+				vm.ToValue(setting.Name),
+				vm.ToValue(value),
+				vm.ToValue(prev[setting.Name]),
+			)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("%s onConfigChange: %w", plugin.Info.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// storeConfig stores the value of a plugin setting in the given guild without
+// notifying the plugin, and returns the setting's new value. A nil value resets
+// the setting to its default.
+func storeConfig(guildID string, info db.PluginInfo, setting db.PluginSetting, value any) (any, error) {
+	if value == nil {
+		value, _ = convertSetting(setting, setting.Default)
+		return value, db.DeletePluginConfig(info.Name, guildID, setting.Name)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return value, db.SetPluginConfig(info.Name, guildID, setting.Name, string(data))
+}
+
+// Config returns the plugin's settings for the given guild
+func (oa *owobotAPI) Config(guildID string) (map[string]any, error) {
+	return guildConfig(oa.PluginInfo, guildID)
+}
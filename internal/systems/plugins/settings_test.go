@@ -0,0 +1,155 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dop251/goja"
+	"go.elara.ws/owobot/internal/db"
+)
+
+func TestValidateSettings(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings []db.PluginSetting
+		valid    bool
+	}{
+		{"valid", []db.PluginSetting{{Name: "a", Type: db.SettingInt, Default: 1.0}, {Name: "b", Type: db.SettingChannel}}, true},
+		{"empty name", []db.PluginSetting{{Type: db.SettingBool}}, false},
+		{"duplicate", []db.PluginSetting{{Name: "a", Type: db.SettingBool}, {Name: "a", Type: db.SettingString}}, false},
+		{"unknown type", []db.PluginSetting{{Name: "a", Type: "float"}}, false},
+		{"invalid default", []db.PluginSetting{{Name: "a", Type: db.SettingInt, Default: 1.5}}, false},
+	}
+
+	for _, c := range cases {
+		if err := validateSettings(c.settings); (err == nil) != c.valid {
+			t.Errorf("%s: validateSettings returned %v, expected valid = %t", c.name, err, c.valid)
+		}
+	}
+}
+
+func TestConvertSetting(t *testing.T) {
+	cases := []struct {
+		typ      db.PluginSettingType
+		value    any
+		expected any
+	}{
+		{db.SettingString, "hi", "hi"},
+		{db.SettingChannel, "123", "123"},
+		{db.SettingInt, 5.0, int64(5)},
+		{db.SettingInt, int64(5), int64(5)},
+		{db.SettingInt, 5.5, nil},
+		{db.SettingInt, "5", nil},
+		{db.SettingBool, true, true},
+		{db.SettingBool, "true", nil},
+	}
+
+	for _, c := range cases {
+		got, err := convertSetting(db.PluginSetting{Name: "s", Type: c.typ}, c.value)
+		if c.expected == nil {
+			if err == nil {
+				t.Errorf("convertSetting(%s, %#v) succeeded with %#v", c.typ, c.value, got)
+			}
+		} else if err != nil || got != c.expected {
+			t.Errorf("convertSetting(%s, %#v) = %#v, %v, expected %#v", c.typ, c.value, got, err, c.expected)
+		}
+	}
+}
+
+func TestSetConfig(t *testing.T) {
+	err := db.Init(context.Background(), "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "configured.js"), []byte(`
+owobot.pluginInfo = {
+	name: "configured",
+	version: "1",
+	desc: "d",
+	settings: [{name: "limit", desc: "d", type: "int", default: 3}],
+}
+var changes = []
+owobot.onConfigChange = function(guildID, name, value, prev) {
+	changes.push(name + ": " + prev + " -> " + value)
+}
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Load(dir, Config{}, &discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Start(&discordgo.Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, plugin := range allPlugins() {
+			unload(plugin)
+		}
+	})
+
+	plugin, ok := findPlugin("configured")
+	if !ok {
+		t.Fatal("plugin wasn't loaded")
+	}
+	setting, ok := findSetting(plugin.Info, "limit")
+	if !ok {
+		t.Fatal("setting wasn't declared")
+	}
+
+	err = setConfig("1", plugin, setting, int64(5))
+	if err == nil {
+		t.Error("configuring a plugin that isn't enabled succeeded")
+	}
+
+	err = db.CreateGuild("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = enablePlugin("1", "configured")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = setConfig("1", plugin, setting, int64(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := guildConfig(plugin.Info, "1")
+	if err != nil {
+		t.Fatal(err)
+	} else if config["limit"] != int64(5) {
+		t.Errorf("expected the setting to be changed to 5, got %#v", config["limit"])
+	}
+
+	err = setConfig("1", plugin, setting, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err = guildConfig(plugin.Info, "1")
+	if err != nil {
+		t.Fatal(err)
+	} else if config["limit"] != int64(3) {
+		t.Errorf("expected the setting to be reset to its default, got %#v", config["limit"])
+	}
+
+	var changes []string
+	err = <-callOnLoop(plugin.api, func(vm *goja.Runtime) error {
+		return vm.ExportTo(vm.Get("changes"), &changes)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0] != "limit: 3 -> 5" || changes[1] != "limit: 5 -> 3" {
+		t.Errorf("unexpected onConfigChange calls: %q", changes)
+	}
+}
@@ -0,0 +1,157 @@
+package plugins
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dop251/goja"
+	"go.elara.ws/logger/log"
+	"go.elara.ws/owobot/internal/shards"
+	"go.elara.ws/owobot/internal/systems/commands"
+	"go.elara.ws/owobot/internal/util"
+)
+
+// SlashCommand represents a first-class discord application command
+// provided by a plugin. It's only registered in guilds where the plugin
+// that provides it is enabled.
+type SlashCommand struct {
+	discordgo.ApplicationCommand
+	OnExec goja.Value
+}
+
+// applicationCommand returns a copy of the command's definition
+// with default values filled in.
+func (sc SlashCommand) applicationCommand() *discordgo.ApplicationCommand {
+	ac := sc.ApplicationCommand
+	if ac.Type == 0 {
+		ac.Type = discordgo.ChatApplicationCommand
+	}
+	if ac.DMPermission == nil {
+		ac.DMPermission = util.Pointer(false)
+	}
+	return &ac
+}
+
+// syncGuildCommands publishes the slash commands of all the plugins
+// enabled in the given guild.
+func syncGuildCommands(s *discordgo.Session, guildID string) error {
+	var gcs []commands.GuildCommand
+	for _, plugin := range allPlugins() {
+		if !pluginEnabled(guildID, plugin.Info.Name) {
+			continue
+		}
+
+		for _, sc := range plugin.SlashCommands {
+			gcs = append(gcs, commands.GuildCommand{
+				Func:    slashCmdFunc(plugin.Info.Name, sc.Name),
+				Command: sc.applicationCommand(),
+			})
+		}
+	}
+	return commands.SetGuildCommands(s, guildID, gcs)
+}
+
+// syncPluginGuilds publishes the slash commands for every guild that has
+// any of the given plugins enabled, logging any errors that occur. Guilds
+// on shards run by other processes are left for those processes to sync.
+func syncPluginGuilds(s *discordgo.Session, pluginNames ...string) {
+	for _, guildID := range guildsWithPlugins(pluginNames...) {
+		if !shards.Local(guildID) {
+			continue
+		}
+
+		err := syncGuildCommands(s, guildID)
+		if err != nil {
+			log.Warn("Error syncing plugin commands").Str("guild-id", guildID).Err(err).Send()
+		}
+	}
+}
+
+// slashCmdFunc returns a command function that executes the given plugin's
+// slash command. The plugin is looked up every time the command is executed
+// so that reloaded plugins are always used.
+func slashCmdFunc(pluginName, cmdName string) commands.CmdFunc {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+		if !pluginEnabled(i.GuildID, pluginName) {
+			return fmt.Errorf("plugin %q is not enabled in this guild", pluginName)
+		}
+
+		plugin, ok := findPlugin(pluginName)
+		if !ok {
+			return fmt.Errorf("no such plugin: %q", pluginName)
+		}
+
+		sc, ok := plugin.findSlashCmd(cmdName)
+		if !ok {
+			return fmt.Errorf("command not found: %q", cmdName)
+		}
+
+		callable, ok := goja.AssertFunction(sc.OnExec)
+		if !ok {
+			return fmt.Errorf("value in onExec is not callable")
+		}
+
+		data := i.ApplicationCommandData()
+		options := resolveOptions(s, i, data.Resolved, data.Options)
+
+		return <-invoke(plugin.api, i.GuildID, "slash command "+cmdName, func(vm *goja.Runtime) error {
+			_, err := callable(
+				vm.ToValue(sc),
+				vm.ToValue(plugin.api.session(s)),
+				vm.ToValue(i),
+				vm.ToValue(options),
+			)
+			return err
+		})
+	}
+}
+
+// findSlashCmd finds the slash command with the given name
+func (p *Plugin) findSlashCmd(name string) (SlashCommand, bool) {
+	for _, sc := range p.SlashCommands {
+		if sc.Name == name {
+			return sc, true
+		}
+	}
+	return SlashCommand{}, false
+}
+
+// resolveOptions converts command options into a map of option names to values.
+// Subcommands and subcommand groups become nested maps, and users, roles, channels,
+// and attachments are resolved into their full objects.
+func resolveOptions(s *discordgo.Session, i *discordgo.InteractionCreate, resolved *discordgo.ApplicationCommandInteractionDataResolved, opts []*discordgo.ApplicationCommandInteractionDataOption) map[string]any {
+	if resolved == nil {
+		resolved = &discordgo.ApplicationCommandInteractionDataResolved{}
+	}
+
+	out := make(map[string]any, len(opts))
+	for _, opt := range opts {
+		switch opt.Type {
+		case discordgo.ApplicationCommandOptionSubCommand, discordgo.ApplicationCommandOptionSubCommandGroup:
+			out[opt.Name] = resolveOptions(s, i, resolved, opt.Options)
+		case discordgo.ApplicationCommandOptionUser:
+			if user, ok := resolved.Users[opt.Value.(string)]; ok {
+				out[opt.Name] = user
+			} else {
+				out[opt.Name] = opt.UserValue(s)
+			}
+		case discordgo.ApplicationCommandOptionRole:
+			if role, ok := resolved.Roles[opt.Value.(string)]; ok {
+				out[opt.Name] = role
+			} else {
+				out[opt.Name] = opt.RoleValue(s, i.GuildID)
+			}
+		case discordgo.ApplicationCommandOptionChannel:
+			if channel, ok := resolved.Channels[opt.Value.(string)]; ok {
+				out[opt.Name] = channel
+			} else {
+				out[opt.Name] = opt.ChannelValue(s)
+			}
+		case discordgo.ApplicationCommandOptionAttachment:
+			out[opt.Name] = resolved.Attachments[opt.Value.(string)]
+		default:
+			out[opt.Name] = opt.Value
+		}
+	}
+	return out
+}
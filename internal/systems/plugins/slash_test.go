@@ -0,0 +1,206 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dop251/goja"
+	"go.elara.ws/owobot/internal/db"
+)
+
+var guildCommandsEndpoint = regexp.MustCompile(`/applications/[^/]+/guilds/(\d+)/commands$`)
+
+// commandRecorder records the names of the commands published to each guild
+// and passes all requests on to a fake discord API.
+type commandRecorder struct {
+	*fakeDiscord
+
+	mu       sync.Mutex
+	commands map[string][]string
+}
+
+func (cr *commandRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	match := guildCommandsEndpoint.FindStringSubmatch(req.URL.Path)
+	if req.Method != http.MethodPut || match == nil {
+		return cr.fakeDiscord.RoundTrip(req)
+	}
+
+	var acs []discordgo.ApplicationCommand
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &acs)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, ac := range acs {
+		names = append(names, ac.Name)
+	}
+
+	cr.mu.Lock()
+	cr.commands[match[1]] = names
+	cr.mu.Unlock()
+
+	return fakeResponse(req, http.StatusOK, data), nil
+}
+
+// published returns the names of the commands last published to the given guild
+func (cr *commandRecorder) published(guildID string) string {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	return strings.Join(cr.commands[guildID], ",")
+}
+
+func TestResolveOptions(t *testing.T) {
+	user := &discordgo.User{ID: "10", Username: "user"}
+	attachment := &discordgo.MessageAttachment{ID: "20", Filename: "a.png"}
+	resolved := &discordgo.ApplicationCommandInteractionDataResolved{
+		Users:       map[string]*discordgo.User{"10": user},
+		Attachments: map[string]*discordgo.MessageAttachment{"20": attachment},
+	}
+
+	options := resolveOptions(&discordgo.Session{}, &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{}}, resolved, []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "set", Type: discordgo.ApplicationCommandOptionSubCommand, Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "text", Type: discordgo.ApplicationCommandOptionString, Value: "hi"},
+			{Name: "count", Type: discordgo.ApplicationCommandOptionInteger, Value: 2.0},
+			{Name: "user", Type: discordgo.ApplicationCommandOptionUser, Value: "10"},
+			{Name: "file", Type: discordgo.ApplicationCommandOptionAttachment, Value: "20"},
+		}},
+	})
+
+	set, ok := options["set"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected the subcommand's options to be nested, got %#v", options)
+	}
+	if set["text"] != "hi" || set["count"] != 2.0 {
+		t.Errorf("unexpected option values: %#v", set)
+	}
+	if set["user"] != user {
+		t.Errorf("expected the user to be resolved, got %#v", set["user"])
+	}
+	if set["file"] != attachment {
+		t.Errorf("expected the attachment to be resolved, got %#v", set["file"])
+	}
+}
+
+func TestSlashCommands(t *testing.T) {
+	err := db.Init(context.Background(), "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "greeter.js"), []byte(`
+owobot.pluginInfo = {name: "greeter", version: "1", desc: "d"}
+var greeted = []
+owobot.slashCommands = [{
+	name: "greet",
+	description: "Greet someone",
+	// Type 3 is a string option
+	options: [{type: 3, name: "name", description: "d", required: true}],
+	onExec: function(s, i, options) { greeted.push(this.name + " " + options.name) },
+}]
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cr := &commandRecorder{fakeDiscord: newFakeDiscord(nil), commands: map[string][]string{}}
+	s := cr.session()
+	s.Client = &http.Client{Transport: cr}
+
+	err = Load(dir, Config{}, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Start(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, plugin := range allPlugins() {
+			unload(plugin)
+		}
+	})
+
+	plugin, ok := findPlugin("greeter")
+	if !ok {
+		t.Fatal("plugin wasn't loaded")
+	}
+
+	for _, guildID := range []string{"1", "2"} {
+		err = db.CreateGuild(guildID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = enable(s, "1", plugin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = syncGuildCommands(s, "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cmds := cr.published("1"); cmds != "greet" {
+		t.Errorf("expected the command to be published in the guild the plugin is enabled in, got %q", cmds)
+	}
+	if cmds := cr.published("2"); cmds != "" {
+		t.Errorf("expected no commands to be published in the guild the plugin isn't enabled in, got %q", cmds)
+	}
+
+	exec := slashCmdFunc("greeter", "greet")
+	run := func(guildID string) error {
+		return exec(s, &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+			Type:    discordgo.InteractionApplicationCommand,
+			GuildID: guildID,
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name: "greet",
+				Options: []*discordgo.ApplicationCommandInteractionDataOption{
+					{Name: "name", Type: discordgo.ApplicationCommandOptionString, Value: "owo"},
+				},
+			},
+		}})
+	}
+
+	if err := run("1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("2"); err == nil {
+		t.Error("command of a plugin that isn't enabled was executed")
+	}
+
+	var greeted []string
+	err = <-callOnLoop(plugin.api, func(vm *goja.Runtime) error {
+		return vm.ExportTo(vm.Get("greeted"), &greeted)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(greeted) != 1 || greeted[0] != "greet owo" {
+		t.Errorf("expected onExec to get the command and its options, got %q", greeted)
+	}
+
+	err = disable(s, "1", plugin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmds := cr.published("1"); cmds != "" {
+		t.Errorf("expected the command to be removed when the plugin is disabled, got %q", cmds)
+	}
+}
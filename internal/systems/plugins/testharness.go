@@ -0,0 +1,326 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dop251/goja"
+	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/events"
+)
+
+// fixture represents a plugin test fixture file
+type fixture struct {
+	GuildID   string `json:"guild_id"`
+	ChannelID string `json:"channel_id"`
+	UserID    string `json:"user_id"`
+
+	// Config contains values for the plugin's settings
+	Config map[string]any `json:"config"`
+
+	// Routes contains canned responses for discord API requests,
+	// keyed by method and path, such as "GET /guilds/1/roles".
+	Routes map[string]json.RawMessage `json:"routes"`
+
+	Steps []fixtureStep `json:"steps"`
+}
+
+// fixtureStep is a single event or command replayed by the test harness
+type fixtureStep struct {
+	Name string `json:"name"`
+
+	// Event is the name of the event type to dispatch, such as "MessageCreate"
+	// or "ticketOpened", and Data is the event's data in the same format discord
+	// sends it in.
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+
+	// Run is a plugin command to execute as if `/plugin run` was used
+	Run string `json:"run"`
+
+	Expect fixtureExpect `json:"expect"`
+}
+
+// fixtureExpect contains the expected results of a fixture step. Expected
+// values only have to contain the fields that should be checked.
+type fixtureExpect struct {
+	Messages  []any  `json:"messages"`
+	Responses []any  `json:"responses"`
+	Error     string `json:"error"`
+}
+
+// fixtureEvents contains constructors for the event types that fixtures may dispatch
+var fixtureEvents = map[string]func() any{
+	"MessageCreate":         func() any { return &discordgo.MessageCreate{} },
+	"MessageUpdate":         func() any { return &discordgo.MessageUpdate{} },
+	"MessageDelete":         func() any { return &discordgo.MessageDelete{} },
+	"MessageReactionAdd":    func() any { return &discordgo.MessageReactionAdd{} },
+	"MessageReactionRemove": func() any { return &discordgo.MessageReactionRemove{} },
+	"GuildMemberAdd":        func() any { return &discordgo.GuildMemberAdd{} },
+	"GuildMemberUpdate":     func() any { return &discordgo.GuildMemberUpdate{} },
+	"GuildMemberRemove":     func() any { return &discordgo.GuildMemberRemove{} },
+	"GuildBanAdd":           func() any { return &discordgo.GuildBanAdd{} },
+	"GuildBanRemove":        func() any { return &discordgo.GuildBanRemove{} },
+	"ChannelCreate":         func() any { return &discordgo.ChannelCreate{} },
+	"ChannelUpdate":         func() any { return &discordgo.ChannelUpdate{} },
+	"ChannelDelete":         func() any { return &discordgo.ChannelDelete{} },
+	"ThreadCreate":          func() any { return &discordgo.ThreadCreate{} },
+	"InteractionCreate":     func() any { return &discordgo.InteractionCreate{} },
+
+	"ticketOpened":      func() any { return &events.TicketOpened{} },
+	"ticketClosed":      func() any { return &events.TicketClosed{} },
+	"vettingApproved":   func() any { return &events.VettingApproved{} },
+	"starboardAdded":    func() any { return &events.StarboardAdded{} },
+	"pollVoted":         func() any { return &events.PollVoted{} },
+	"rateLimitWarning":  func() any { return &events.RateLimitWarning{} },
+	"rateLimitExceeded": func() any { return &events.RateLimitExceeded{} },
+}
+
+// Test loads the plugin at path the same way [Load] does, but against a fake
+// discord session and an in-memory database. It then replays the steps in the
+// fixture file, writing the result of each one to w, and returns an error if
+// any of the fixture's expectations weren't met.
+func Test(path, fixturePath string, config Config, w io.Writer) error {
+	data, err := os.ReadFile(fixturePath)
+	if err != nil {
+		return err
+	}
+
+	var fx fixture
+	err = json.Unmarshal(data, &fx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fixturePath, err)
+	}
+
+	if fx.GuildID == "" {
+		fx.GuildID = "1"
+	}
+	if fx.ChannelID == "" {
+		fx.ChannelID = "2"
+	}
+	if fx.UserID == "" {
+		fx.UserID = "3"
+	}
+
+	err = db.Init(context.Background(), "file::memory:?cache=shared")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = db.CreateGuild(fx.GuildID)
+	if err != nil {
+		return err
+	}
+
+	// Plugins are never reloaded during tests
+	config.WatchInterval = 0
+	cfg = config
+
+	fd := newFakeDiscord(fx.Routes)
+	s := fd.session()
+
+	plugin, err := loadAndInitPlugin(path, s)
+	if err != nil {
+		return err
+	} else if plugin == nil {
+		return fmt.Errorf("%s: plugin info not provided", path)
+	}
+	addPlugin(plugin)
+	defer unload(plugin)
+
+	for name, value := range fx.Config {
+		setting, ok := findSetting(plugin.Info, name)
+		if !ok {
+			return fmt.Errorf("plugin %q has no setting named %q", plugin.Info.Name, name)
+		}
+
+		v, err := convertSetting(setting, value)
+		if err != nil {
+			return fmt.Errorf("setting %q: %w", name, err)
+		}
+
+		// The configuration is stored before the plugin is enabled,
+		// so its onConfigChange hook isn't called for it.
+		_, err = storeConfig(fx.GuildID, plugin.Info, setting, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Exceptions thrown by event handlers don't reach the code that dispatched
+	// the event, so they're collected here to fail the step they happened in.
+	var handlerErrs []error
+	plugin.api.onError = func(source string, err error) {
+		handlerErrs = append(handlerErrs, fmt.Errorf("%s: %w", source, err))
+	}
+
+	err = enable(s, fx.GuildID, plugin)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for i, step := range fx.Steps {
+		name := step.Name
+		if name == "" {
+			name = "step " + strconv.Itoa(i+1)
+		}
+
+		fd.reset()
+		handlerErrs = nil
+		stepErr := runStep(s, fx, plugin, i, step)
+		// Once runStep returns, all the handlers dispatched by the step have run,
+		// so handlerErrs isn't being modified by the plugin's event loop anymore.
+		// Errors from commands are also returned by runStep, so they're skipped.
+		for _, herr := range handlerErrs {
+			if stepErr == nil || !errors.Is(herr, stepErr) {
+				stepErr = errors.Join(stepErr, herr)
+			}
+		}
+		err := checkStep(fd, step.Expect, stepErr)
+
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "FAIL %s: %s\n", name, err)
+		} else {
+			fmt.Fprintf(w, "PASS %s\n", name)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d steps failed", failed, len(fx.Steps))
+	}
+	return nil
+}
+
+// runStep replays a single fixture step and waits for the plugin to handle it
+func runStep(s *discordgo.Session, fx fixture, plugin *Plugin, index int, step fixtureStep) error {
+	switch {
+	case step.Event != "":
+		newEvent, ok := fixtureEvents[step.Event]
+		if !ok {
+			return fmt.Errorf("unsupported event type: %q", step.Event)
+		}
+
+		event := newEvent()
+		if len(step.Data) > 0 {
+			err := json.Unmarshal(step.Data, event)
+			if err != nil {
+				return fmt.Errorf("invalid %s data: %w", step.Event, err)
+			}
+		}
+
+		if e, ok := event.(events.Event); ok {
+			handleCoreEvent(s, e)
+		} else {
+			handlePluginEvent(s, event)
+		}
+	case step.Run != "":
+		err := pluginCmd(s, &discordgo.InteractionCreate{
+			Interaction: &discordgo.Interaction{
+				ID:        "interaction-" + strconv.Itoa(index+1),
+				Token:     "token",
+				Type:      discordgo.InteractionApplicationCommand,
+				GuildID:   fx.GuildID,
+				ChannelID: fx.ChannelID,
+				Member: &discordgo.Member{
+					GuildID:     fx.GuildID,
+					User:        &discordgo.User{ID: fx.UserID},
+					Permissions: discordgo.PermissionAll,
+				},
+				Data: discordgo.ApplicationCommandInteractionData{
+					Name: "plugin",
+					Options: []*discordgo.ApplicationCommandInteractionDataOption{{
+						Name: "run",
+						Type: discordgo.ApplicationCommandOptionSubCommand,
+						Options: []*discordgo.ApplicationCommandInteractionDataOption{{
+							Name:  "cmd",
+							Type:  discordgo.ApplicationCommandOptionString,
+							Value: step.Run,
+						}},
+					}},
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.New("step has neither an event nor a command to run")
+	}
+
+	// Jobs on the event loop run in order, so once this one runs,
+	// any event handlers dispatched by the step have completed.
+	return <-callOnLoop(plugin.api, func(*goja.Runtime) error { return nil })
+}
+
+// checkStep checks the results of a step against its expectations
+func checkStep(fd *fakeDiscord, expect fixtureExpect, stepErr error) error {
+	if expect.Error != "" {
+		if stepErr == nil {
+			return fmt.Errorf("expected error containing %q, got none", expect.Error)
+		} else if !strings.Contains(stepErr.Error(), expect.Error) {
+			return fmt.Errorf("expected error containing %q, got %q", expect.Error, stepErr)
+		}
+	} else if stepErr != nil {
+		return stepErr
+	}
+
+	messages, responses := fd.results()
+	if expect.Messages != nil && !matches(expect.Messages, messages) {
+		return fmt.Errorf("expected messages %s, got %s", toJSON(expect.Messages), toJSON(messages))
+	}
+	if expect.Responses != nil && !matches(expect.Responses, responses) {
+		return fmt.Errorf("expected interaction responses %s, got %s", toJSON(expect.Responses), toJSON(responses))
+	}
+	return nil
+}
+
+// matches checks whether actual matches expected. Objects in actual may contain
+// fields that aren't in expected, but arrays must have the same length.
+func matches(expected, actual any) bool {
+	switch expected := expected.(type) {
+	case map[string]any:
+		actual, ok := actual.(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, value := range expected {
+			if !matches(value, actual[key]) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		actual, ok := actual.([]any)
+		if !ok || len(actual) != len(expected) {
+			return false
+		}
+		for i := range expected {
+			if !matches(expected[i], actual[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(expected, actual)
+	}
+}
+
+// toJSON encodes v as JSON for use in error messages
+func toJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,141 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestMatches(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected string
+		actual   string
+		match    bool
+	}{
+		{"equal", `{"a": 1}`, `{"a": 1}`, true},
+		{"extra fields", `{"a": 1}`, `{"a": 1, "b": 2}`, true},
+		{"missing field", `{"a": 1, "b": 2}`, `{"a": 1}`, false},
+		{"different value", `{"a": 1}`, `{"a": 2}`, false},
+		{"nested", `{"a": {"b": [1, {"c": "x"}]}}`, `{"a": {"b": [1, {"c": "x", "d": true}]}}`, true},
+		{"array length", `[1, 2]`, `[1, 2, 3]`, false},
+		{"empty array", `[]`, `[]`, true},
+		{"type mismatch", `{"a": [1]}`, `{"a": {"0": 1}}`, false},
+		{"null", `{"a": null}`, `{}`, true},
+	}
+
+	for _, c := range cases {
+		var expected, actual any
+		if err := json.Unmarshal([]byte(c.expected), &expected); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(c.actual), &actual); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := matches(expected, actual); got != c.match {
+			t.Errorf("%s: matches(%s, %s) = %t, expected %t", c.name, c.expected, c.actual, got, c.match)
+		}
+	}
+}
+
+func TestFakeDiscord(t *testing.T) {
+	fd := newFakeDiscord(map[string]json.RawMessage{
+		"GET /guilds/1/roles": json.RawMessage(`[{"id": "5", "name": "mod"}]`),
+	})
+	s := fd.session()
+
+	roles, err := s.GuildRoles("1")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(roles) != 1 || roles[0].Name != "mod" {
+		t.Errorf("expected the canned roles, got %+v", roles)
+	}
+
+	msg, err := s.ChannelMessageSend("2", "hello")
+	if err != nil {
+		t.Fatal(err)
+	} else if msg.ID == "" || msg.ChannelID != "2" {
+		t.Errorf("expected the sent message to get an ID and channel ID, got %+v", msg)
+	}
+
+	err = s.InteractionRespond(&discordgo.Interaction{ID: "3", Token: "tok"}, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: "hi"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages, responses := fd.results()
+	if !matches([]any{map[string]any{"content": "hello", "channel_id": "2"}}, messages) {
+		t.Errorf("unexpected messages: %s", toJSON(messages))
+	}
+	if !matches([]any{map[string]any{"data": map[string]any{"content": "hi"}}}, responses) {
+		t.Errorf("unexpected interaction responses: %s", toJSON(responses))
+	}
+
+	fd.reset()
+	messages, responses = fd.results()
+	if len(messages) != 0 || len(responses) != 0 {
+		t.Errorf("expected reset to clear the results, got %s and %s", toJSON(messages), toJSON(responses))
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, "https://discord.com/api/v9/channels/2", strings.NewReader(`{"name": "x"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := fd.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var echoed map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&echoed); err != nil {
+		t.Fatal(err)
+	} else if echoed["name"] != "x" {
+		t.Errorf("expected the request body to be echoed back, got %v", echoed)
+	}
+}
+
+func TestHarnessFailsOnHandlerErrors(t *testing.T) {
+	dir := t.TempDir()
+	pluginPath := filepath.Join(dir, "thrower.js")
+	fixturePath := filepath.Join(dir, "thrower.json")
+
+	err := os.WriteFile(pluginPath, []byte(`
+owobot.pluginInfo = {name: "thrower", version: "1", desc: "d"}
+owobot.on("MessageCreate", function(s, m) {
+	throw new Error("handler exploded")
+})
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(fixturePath, []byte(`{"steps": [
+	{"name": "throws", "event": "MessageCreate", "data": {"guild_id": "1", "channel_id": "2"}},
+	{"name": "expected", "event": "MessageCreate", "data": {"guild_id": "1", "channel_id": "2"}, "expect": {"error": "handler exploded"}}
+]}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	err = Test(pluginPath, fixturePath, Config{}, out)
+	if err == nil {
+		t.Fatal("expected the test to fail")
+	}
+
+	if !strings.Contains(out.String(), "FAIL throws") {
+		t.Errorf("expected the step whose handler threw to fail: %s", out)
+	}
+	if !strings.Contains(out.String(), "PASS expected") {
+		t.Errorf("expected the step that expects the error to pass: %s", out)
+	}
+}
@@ -0,0 +1,639 @@
+package plugins
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dop251/goja"
+	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/events"
+	"go.elara.ws/owobot/internal/systems/plugins/builtins"
+)
+
+// discordEvents contains the discord events that plugins can handle with owobot.on
+var discordEvents = []any{
+	discordgo.Connect{},
+	discordgo.Disconnect{},
+	discordgo.RateLimit{},
+	discordgo.Ready{},
+	discordgo.Resumed{},
+	discordgo.ChannelCreate{},
+	discordgo.ChannelUpdate{},
+	discordgo.ChannelDelete{},
+	discordgo.ChannelPinsUpdate{},
+	discordgo.ThreadCreate{},
+	discordgo.ThreadUpdate{},
+	discordgo.ThreadDelete{},
+	discordgo.ThreadListSync{},
+	discordgo.ThreadMemberUpdate{},
+	discordgo.ThreadMembersUpdate{},
+	discordgo.GuildCreate{},
+	discordgo.GuildUpdate{},
+	discordgo.GuildDelete{},
+	discordgo.GuildBanAdd{},
+	discordgo.GuildBanRemove{},
+	discordgo.GuildMemberAdd{},
+	discordgo.GuildMemberUpdate{},
+	discordgo.GuildMemberRemove{},
+	discordgo.GuildRoleCreate{},
+	discordgo.GuildRoleUpdate{},
+	discordgo.GuildRoleDelete{},
+	discordgo.GuildEmojisUpdate{},
+	discordgo.GuildMembersChunk{},
+	discordgo.GuildIntegrationsUpdate{},
+	discordgo.GuildAuditLogEntryCreate{},
+	discordgo.StageInstanceEventCreate{},
+	discordgo.StageInstanceEventUpdate{},
+	discordgo.StageInstanceEventDelete{},
+	discordgo.GuildScheduledEventCreate{},
+	discordgo.GuildScheduledEventUpdate{},
+	discordgo.GuildScheduledEventDelete{},
+	discordgo.GuildScheduledEventUserAdd{},
+	discordgo.GuildScheduledEventUserRemove{},
+	discordgo.MessageCreate{},
+	discordgo.MessageUpdate{},
+	discordgo.MessageDelete{},
+	discordgo.MessageDeleteBulk{},
+	discordgo.MessageReactionAdd{},
+	discordgo.MessageReactionRemove{},
+	discordgo.MessageReactionRemoveAll{},
+	discordgo.PresenceUpdate{},
+	discordgo.TypingStart{},
+	discordgo.UserUpdate{},
+	discordgo.VoiceServerUpdate{},
+	discordgo.VoiceStateUpdate{},
+	discordgo.WebhooksUpdate{},
+	discordgo.InteractionCreate{},
+	discordgo.InviteCreate{},
+	discordgo.InviteDelete{},
+	discordgo.ApplicationCommandPermissionsUpdate{},
+	discordgo.AutoModerationRuleCreate{},
+	discordgo.AutoModerationRuleUpdate{},
+	discordgo.AutoModerationRuleDelete{},
+	discordgo.AutoModerationActionExecution{},
+}
+
+// coreEvents contains owobot's own events that plugins can handle with owobot.on
+var coreEvents = []events.Event{
+	events.TicketOpened{},
+	events.TicketClosed{},
+	events.VettingApproved{},
+	events.StarboardAdded{},
+	events.PollVoted{},
+	events.RateLimitWarning{},
+	events.RateLimitExceeded{},
+}
+
+//go:generate go run gen_paramnames.go
+
+var (
+	valueType    = reflect.TypeOf((*goja.Value)(nil)).Elem()
+	callableType = reflect.TypeOf(goja.Callable(nil))
+	promiseType  = reflect.TypeOf(&goja.Promise{})
+	bufferType   = reflect.TypeOf(goja.ArrayBuffer{})
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	apiType      = reflect.TypeOf(&owobotAPI{})
+)
+
+// WriteTypes writes TypeScript declarations for the JavaScript plugin API to w.
+// The declarations are generated by reflecting on the values exposed to plugins
+// the same way the JavaScript runtime does.
+func WriteTypes(w io.Writer) error {
+	return newTypeGen().write(w)
+}
+
+// newTypeGen creates a new TypeScript type generator
+func newTypeGen() *typeGen {
+	return &typeGen{
+		names: map[reflect.Type]string{},
+		used:  map[string]reflect.Type{},
+	}
+}
+
+// write writes the declarations for the JavaScript plugin API to w
+func (g *typeGen) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("// Code generated by `owobot plugin types`. DO NOT EDIT.\n\n")
+
+	bw.WriteString("declare const owobot: " + g.tsType(apiType) + ";\n")
+	bw.WriteString("declare const discord: " + g.valueType(reflect.ValueOf(builtins.Constants), "") + ";\n")
+	bw.WriteString("declare const log: " + g.tsType(reflect.TypeOf(logAPI{})) + ";\n")
+	params, result := g.funcSignature(logAPI{}.print)
+	bw.WriteString("declare function print(" + params + "): " + result + ";\n")
+	bw.WriteString("declare function require(id: string): any;\n")
+	bw.WriteString("declare const module: { exports: any };\n")
+	bw.WriteString("declare let exports: any;\n")
+	bw.WriteString("declare function setTimeout(fn: (...args: any[]) => void, delay?: number, ...args: any[]): Timer;\n")
+	bw.WriteString("declare function setInterval(fn: (...args: any[]) => void, delay?: number, ...args: any[]): Interval;\n")
+	bw.WriteString("declare function clearTimeout(timer: Timer): void;\n")
+	bw.WriteString("declare function clearInterval(interval: Interval): void;\n")
+	bw.WriteString("// Timer and Interval are opaque handles returned by setTimeout and setInterval\n")
+	bw.WriteString("interface Timer {}\n")
+	bw.WriteString("interface Interval {}\n")
+
+	globals := builtins.Globals()
+	namespaces := map[string][]string{}
+	for _, name := range sortedKeys(globals) {
+		value := globals[name]
+
+		if ns, prop, ok := strings.Cut(name, "."); ok {
+			params, result := g.funcSignature(value)
+			namespaces[ns] = append(namespaces[ns], fmt.Sprintf("\tfunction %s(%s): %s;\n", prop, params, result))
+			continue
+		}
+
+		switch value := value.(type) {
+		case reflect.Type:
+			fmt.Fprintf(bw, "declare const %s: { new (): %s };\n", name, g.tsType(value))
+		default:
+			t := reflect.TypeOf(value)
+			if t.Kind() == reflect.Func {
+				params, result := g.funcSignature(value)
+				fmt.Fprintf(bw, "declare function %s(%s): %s;\n", name, params, result)
+			} else {
+				fmt.Fprintf(bw, "declare const %s: %s;\n", name, g.tsType(t))
+			}
+		}
+	}
+
+	for _, ns := range sortedKeys(namespaces) {
+		bw.WriteString("declare namespace " + ns + " {\n")
+		for _, fn := range namespaces[ns] {
+			bw.WriteString(fn)
+		}
+		bw.WriteString("}\n")
+	}
+
+	bw.WriteString("\n// EventMap maps the names of the events plugins can handle to their data\n")
+	bw.WriteString("interface EventMap {\n")
+	for _, evt := range discordEvents {
+		t := reflect.TypeOf(evt)
+		fmt.Fprintf(bw, "\t%s: %s;\n", t.Name(), g.tsType(t))
+	}
+	for _, evt := range coreEvents {
+		fmt.Fprintf(bw, "\t%s: %s;\n", evt.Name(), g.tsType(reflect.TypeOf(evt)))
+	}
+	bw.WriteString("}\n")
+
+	// Writing an interface may discover more types, so the queue
+	// has to be checked again after every interface.
+	for i := 0; i < len(g.queue); i++ {
+		bw.WriteString("\n")
+		g.writeInterface(bw, g.queue[i])
+	}
+
+	return bw.Flush()
+}
+
+// typeGen generates TypeScript types from Go types
+type typeGen struct {
+	// names maps the Go types that have been given interface names to those names
+	names map[reflect.Type]string
+	// used maps interface names to the Go types they were given to
+	used map[string]reflect.Type
+	// queue contains the types whose interfaces still have to be written
+	queue []reflect.Type
+	// missing contains the methods that aren't in paramNames,
+	// which means paramnames.go has to be regenerated.
+	missing []string
+}
+
+// tsType returns the TypeScript type for values of the given Go type
+func (g *typeGen) tsType(t reflect.Type) string {
+	switch t {
+	case valueType, errorType:
+		return "any"
+	case callableType:
+		return "(...args: any[]) => any"
+	case promiseType:
+		return "Promise<any>"
+	case bufferType:
+		return "ArrayBuffer"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		elem := g.tsType(t.Elem())
+		if strings.Contains(elem, "=>") || strings.Contains(elem, "|") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return "Record<string, " + g.tsType(t.Elem()) + ">"
+		}
+		return "any"
+	case reflect.Pointer:
+		return g.tsType(t.Elem())
+	case reflect.Func:
+		params, result := g.signature(t, nil)
+		return "(" + params + ") => " + result
+	case reflect.Struct:
+		if t.Name() == "" {
+			// Anonymous structs are written inline
+			sb := &strings.Builder{}
+			sb.WriteString("{ ")
+			for _, f := range g.fields(t) {
+				fmt.Fprintf(sb, "%s?: %s; ", f.name, g.tsType(f.typ))
+			}
+			sb.WriteString("}")
+			return sb.String()
+		}
+		return g.name(t)
+	default:
+		return "any"
+	}
+}
+
+// valueType returns the TypeScript type of a value. Maps are described by
+// their contents rather than their type, which is used for constants.
+func (g *typeGen) valueType(v reflect.Value, indent string) string {
+	for v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return g.tsType(v.Type())
+	}
+
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	sb := &strings.Builder{}
+	sb.WriteString("{\n")
+	for _, key := range keys {
+		fmt.Fprintf(sb, "%s\treadonly %s: %s;\n", indent, key.String(), g.valueType(v.MapIndex(key), indent+"\t"))
+	}
+	sb.WriteString(indent + "}")
+	return sb.String()
+}
+
+// name returns the interface name for the given struct type,
+// queueing its interface to be written if it's new.
+func (g *typeGen) name(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := exportedName(t.Name())
+	if _, ok := g.used[name]; ok {
+		// Another package has a type with the same name,
+		// so this one is prefixed with its package name.
+		name = exportedName(path.Base(t.PkgPath())) + name
+	}
+	for i := 2; g.used[name] != nil; i++ {
+		name = fmt.Sprintf("%s%d", strings.TrimRight(name, "0123456789"), i)
+	}
+
+	g.names[t] = name
+	g.used[name] = t
+	g.queue = append(g.queue, t)
+	return name
+}
+
+// typeField is a field of a Go struct as seen from JavaScript
+type typeField struct {
+	name   string
+	goName string
+	typ    reflect.Type
+}
+
+// fields returns the fields of the given struct type that are visible from JavaScript.
+// Like in the JavaScript runtime, fields of embedded structs are promoted unless the
+// outer struct has a field with the same name.
+func (g *typeGen) fields(t reflect.Type) []typeField {
+	var (
+		out    []typeField
+		depths = map[string]int{}
+	)
+
+	var walk func(t reflect.Type, depth int)
+	walk = func(t reflect.Type, depth int) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			name := toLowerCamel(f.Name)
+			if d, ok := depths[name]; !ok {
+				depths[name] = depth
+				out = append(out, typeField{name: name, goName: f.Name, typ: f.Type})
+			} else if d > depth {
+				depths[name] = depth
+				i := slices.IndexFunc(out, func(tf typeField) bool { return tf.name == name })
+				out[i].goName = f.Name
+				out[i].typ = f.Type
+			}
+
+			if f.Anonymous {
+				ft := f.Type
+				for ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					walk(ft, depth+1)
+				}
+			}
+		}
+	}
+	walk(t, 0)
+
+	return out
+}
+
+// signature returns the TypeScript parameter list and result type of a function type,
+// using the given parameter names where they're known. Like in the JavaScript runtime,
+// a trailing error result is thrown rather than returned, and multiple results are
+// returned as an array.
+func (g *typeGen) signature(t reflect.Type, names []string) (params, result string) {
+	return g.signatureFrom(t, 0, names, nil, "")
+}
+
+// funcSignature is like signature, but it looks up the parameter names of fn,
+// which must be a method value.
+func (g *typeGen) funcSignature(fn any) (params, result string) {
+	v := reflect.ValueOf(fn)
+	key := funcKey(v)
+	names, ok := paramNames[key]
+	if !ok && v.Type().NumIn() > 0 {
+		g.missing = append(g.missing, key)
+	}
+	return g.signature(v.Type(), names)
+}
+
+// methodParamNames returns the parameter names of the method of struct type t
+// with the given name. Methods promoted from embedded structs are looked up on
+// the struct that declares them. Methods that can't be found are recorded in g.missing.
+func (g *typeGen) methodParamNames(t reflect.Type, name string) []string {
+	key := t.PkgPath() + "." + t.Name() + "." + name
+	if names, ok := paramNames[key]; ok {
+		return names
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.Anonymous {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if _, ok := reflect.PointerTo(ft).MethodByName(name); ok && ft.Kind() == reflect.Struct {
+			return g.methodParamNames(ft, name)
+		}
+	}
+
+	g.missing = append(g.missing, key)
+	return nil
+}
+
+// funcKey returns the key of a method value in paramNames. The runtime names
+// method values like "pkg.(*T).Method-fm", while paramNames uses "pkg.T.Method".
+func funcKey(v reflect.Value) string {
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+	name := strings.TrimSuffix(f.Name(), "-fm")
+	return strings.NewReplacer("(*", "", ")", "").Replace(name)
+}
+
+// signatureFrom is like signature, but it skips the first skip parameters, such as
+// method receivers. names doesn't include the skipped parameters. If recv and method
+// are set, parameters listed in callbacks get their callback types.
+func (g *typeGen) signatureFrom(t reflect.Type, skip int, names []string, recv reflect.Type, method string) (params, result string) {
+	// Missing arguments are passed as zero values, so trailing parameters
+	// that have a natural empty value are marked as optional.
+	optionalFrom := t.NumIn()
+	for i := t.NumIn() - 1; i >= skip; i-- {
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			optionalFrom = i
+			continue
+		}
+		if i-skip < len(names) {
+			if _, ok := g.callbackType(recv, method+"."+names[i-skip]); ok {
+				// Callbacks are required even though they're passed as goja values
+				break
+			}
+		}
+		switch t.In(i).Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			optionalFrom = i
+			continue
+		}
+		break
+	}
+
+	var ps []string
+	for i := skip; i < t.NumIn(); i++ {
+		name := fmt.Sprintf("arg%d", i-skip)
+		if i-skip < len(names) && names[i-skip] != "" {
+			name = names[i-skip]
+		}
+
+		typ, ok := g.callbackType(recv, method+"."+name)
+		if !ok {
+			typ = g.tsType(t.In(i))
+		}
+
+		switch {
+		case t.IsVariadic() && i == t.NumIn()-1:
+			ps = append(ps, fmt.Sprintf("...%s: %s", name, typ))
+		case i >= optionalFrom:
+			ps = append(ps, fmt.Sprintf("%s?: %s", name, typ))
+		default:
+			ps = append(ps, fmt.Sprintf("%s: %s", name, typ))
+		}
+	}
+
+	var rs []string
+	for i := 0; i < t.NumOut(); i++ {
+		if i == t.NumOut()-1 && t.Out(i) == errorType {
+			break
+		}
+		rs = append(rs, g.tsType(t.Out(i)))
+	}
+
+	switch len(rs) {
+	case 0:
+		result = "void"
+	case 1:
+		result = rs[0]
+	default:
+		result = "[" + strings.Join(rs, ", ") + "]"
+	}
+
+	return strings.Join(ps, ", "), result
+}
+
+// writeInterface writes the interface for the given struct type. Fields are optional,
+// since plugins may leave them out of the objects they pass to owobot.
+func (g *typeGen) writeInterface(w *bufio.Writer, t reflect.Type) {
+	fmt.Fprintf(w, "interface %s {\n", g.names[t])
+
+	fields := g.fields(t)
+	for _, f := range fields {
+		typ, ok := g.callbackType(t, f.goName)
+		if !ok {
+			typ = g.tsType(f.typ)
+		}
+		fmt.Fprintf(w, "\t%s?: %s;\n", f.name, typ)
+	}
+
+	// Methods are looked up on the pointer type, since the runtime
+	// makes methods with both receiver types available.
+	pt := reflect.PointerTo(t)
+	for i := 0; i < pt.NumMethod(); i++ {
+		m := pt.Method(i)
+		name := toLowerCamel(m.Name)
+		if slices.ContainsFunc(fields, func(f typeField) bool { return f.name == name }) {
+			// Fields take precedence over methods with the same name
+			continue
+		}
+
+		if override, ok := g.methodOverride(pt, name); ok {
+			w.WriteString(override)
+			continue
+		}
+
+		var names []string
+		if m.Type.NumIn() > 1 {
+			names = g.methodParamNames(t, m.Name)
+		}
+		params, result := g.signatureFrom(m.Type, 1, names, t, m.Name)
+		fmt.Fprintf(w, "\t%s(%s): %s;\n", name, params, result)
+	}
+
+	w.WriteString("}\n")
+}
+
+// methodOverride returns a hand-written declaration for methods whose
+// Go signatures don't describe the values they're called with.
+func (g *typeGen) methodOverride(t reflect.Type, name string) (string, bool) {
+	if t != apiType || name != "on" {
+		return "", false
+	}
+
+	api := g.tsType(apiType)
+	session := g.tsType(reflect.TypeOf(&discordgo.Session{}))
+	return fmt.Sprintf(
+		"\ton<K extends keyof EventMap>(eventType: K, fn: (this: %[1]s, s: %[2]s, data: EventMap[K]) => void): void;\n"+
+			"\ton(eventType: string, fn: (this: %[1]s, s: %[2]s, data: any) => void): void;\n",
+		api, session,
+	), true
+}
+
+// callbackSpec describes a callback that plugins pass to owobot
+type callbackSpec struct {
+	// params is the callback's parameter list, where the names in
+	// callbackPlaceholders are replaced with TypeScript types.
+	params string
+	result string
+	// unbound is set for callbacks that are called with this undefined rather than the receiver
+	unbound bool
+}
+
+// callbackPlaceholders contains the types that can be used in the parameters of callbacks.
+// {recv} is the type of the value the callback is set on or passed to.
+var callbackPlaceholders = map[string]reflect.Type{
+	"{session}":     reflect.TypeOf(&discordgo.Session{}),
+	"{interaction}": reflect.TypeOf(&discordgo.InteractionCreate{}),
+	"{component}":   reflect.TypeOf(discordgo.MessageComponentInteractionData{}),
+	"{pluginInfo}":  reflect.TypeOf(db.PluginInfo{}),
+}
+
+// callbacks contains the callbacks plugins pass to owobot, keyed by receiver type and
+// field, or receiver type, method, and parameter. Reflection only sees these as goja
+// values, so their types have to be written by hand.
+var callbacks = map[string]callbackSpec{
+	"owobotAPI.Init":           {params: "prev: {pluginInfo}, s: {session}", result: "void"},
+	"owobotAPI.OnEnable":       {params: "guildID: string", result: "void"},
+	"owobotAPI.OnDisable":      {params: "guildID: string", result: "void"},
+	"owobotAPI.OnUnload":       {result: "void"},
+	"owobotAPI.OnConfigChange": {params: "guildID: string, name: string, value: any, prev: any", result: "void"},
+	"owobotAPI.Schedule.fn":    {params: "s: {session}, guildID: string", result: "void"},
+	"owobotAPI.OnTimer.fn":     {params: "s: {session}, payload: any", result: "void"},
+	"owobotAPI.OnComponent.fn": {params: "s: {session}, interaction: {interaction}, id: string, data: {component}", result: "void"},
+	"owobotAPI.OnModal.fn":     {params: "s: {session}, interaction: {interaction}, id: string, data: Record<string, string>", result: "void"},
+	"Command.OnExec":           {params: "s: {session}, interaction: {interaction}, args: string[]", result: "void"},
+	"Command.OnAutocomplete":   {params: "args: string[], partial: string", result: "any"},
+	"SlashCommand.OnExec":      {params: "s: {session}, interaction: {interaction}, options: Record<string, any>", result: "void"},
+	"sqlAPI.Transaction.fn":    {params: "sql: {recv}", result: "any", unbound: true},
+}
+
+// callbackType returns the TypeScript type of the given member of recv
+// if it's a callback.
+func (g *typeGen) callbackType(recv reflect.Type, member string) (string, bool) {
+	if recv == nil {
+		return "", false
+	}
+	for recv.Kind() == reflect.Pointer {
+		recv = recv.Elem()
+	}
+	spec, ok := callbacks[recv.Name()+"."+member]
+	if !ok {
+		return "", false
+	}
+
+	replacements := []string{"{recv}", g.tsType(recv)}
+	for _, placeholder := range sortedKeys(callbackPlaceholders) {
+		if strings.Contains(spec.params, placeholder) {
+			replacements = append(replacements, placeholder, g.tsType(callbackPlaceholders[placeholder]))
+		}
+	}
+
+	params := "this: " + g.tsType(recv)
+	if spec.unbound {
+		params = "this: void"
+	}
+	if spec.params != "" {
+		params += ", " + strings.NewReplacer(replacements...).Replace(spec.params)
+	}
+	return "(" + params + ") => " + spec.result, true
+}
+
+// exportedName converts a Go identifier into an exported TypeScript type name
+func exportedName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return -1
+	}, name)
+	if name == "" {
+		return "Anonymous"
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,89 @@
+package plugins
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func writeTestTypes(t *testing.T) (string, *typeGen) {
+	t.Helper()
+	g := newTypeGen()
+	sb := &strings.Builder{}
+	err := g.write(sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sb.String(), g
+}
+
+func TestTypesParamNamesUpToDate(t *testing.T) {
+	_, g := writeTestTypes(t)
+	if len(g.missing) != 0 {
+		t.Errorf("paramnames.go is missing %d methods, run go generate: %q", len(g.missing), g.missing)
+	}
+}
+
+func TestTypesParamNames(t *testing.T) {
+	out, _ := writeTestTypes(t)
+
+	expected := []string{
+		"\temit(name: string, payload?: any): void;\n",
+		"\trunAt(name: string, at?: any, payload?: any): number;\n",
+		"declare function print(...args: any[]): void;\n",
+		"\tfunction async(url: string, opts?: ",
+	}
+	for _, decl := range expected {
+		if !strings.Contains(out, decl) {
+			t.Errorf("expected the declarations to contain %q", decl)
+		}
+	}
+
+	// Parameters of methods with named parameters shouldn't fall back to generated names.
+	// Function types in parameters don't have parameter names, so they're ignored.
+	method := regexp.MustCompile(`(?m)^\t\w+\((.*)\): .*;$`)
+	funcType := regexp.MustCompile(`\([^()]*\) =>`)
+	for _, m := range method.FindAllStringSubmatch(out, -1) {
+		if strings.Contains(funcType.ReplaceAllString(m[1], ""), "arg0") {
+			t.Errorf("method declaration has a generated parameter name: %q", m[0])
+		}
+	}
+}
+
+func TestTypesCallbacks(t *testing.T) {
+	out, _ := writeTestTypes(t)
+
+	expected := []string{
+		"\tschedule(name: string, expr: string, fn: (this: OwobotAPI, s: Session, guildID: string) => void): void;\n",
+		"\tonTimer(name: string, fn: (this: OwobotAPI, s: Session, payload: any) => void): void;\n",
+		"\tonModal(prefix: string, fn: (this: OwobotAPI, s: Session, interaction: InteractionCreate, id: string, data: Record<string, string>) => void): void;\n",
+		"\tinit?: (this: OwobotAPI, prev: PluginInfo, s: Session) => void;\n",
+		"\tonEnable?: (this: OwobotAPI, guildID: string) => void;\n",
+		"\tonUnload?: (this: OwobotAPI) => void;\n",
+		"\tonExec?: (this: Command, s: Session, interaction: InteractionCreate, args: string[]) => void;\n",
+		"\tonExec?: (this: SlashCommand, s: Session, interaction: InteractionCreate, options: Record<string, any>) => void;\n",
+		"\ttransaction(fn: (this: void, sql: SqlAPI) => any): any;\n",
+	}
+	for _, decl := range expected {
+		if !strings.Contains(out, decl) {
+			t.Errorf("expected the declarations to contain %q", decl)
+		}
+	}
+}
+
+func TestTypesLoopGlobals(t *testing.T) {
+	out, _ := writeTestTypes(t)
+
+	for _, name := range []string{
+		"declare function setTimeout(",
+		"declare function setInterval(",
+		"declare function clearTimeout(",
+		"declare function clearInterval(",
+		"declare const module: ",
+		"declare let exports: ",
+	} {
+		if !strings.Contains(out, name) {
+			t.Errorf("expected the declarations to contain %q", name)
+		}
+	}
+}
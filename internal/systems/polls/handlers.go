@@ -15,6 +15,7 @@ import (
 	"go.elara.ws/logger/log"
 	"go.elara.ws/owobot/internal/db"
 	"go.elara.ws/owobot/internal/emoji"
+	"go.elara.ws/owobot/internal/events"
 	"go.elara.ws/owobot/internal/util"
 	"go.elara.ws/owobot/internal/xsync"
 )
@@ -261,6 +262,14 @@ func onVote(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		log.Error("Error editing poll message").Err(err).Send()
 		return
 	}
+
+	// The event is only published once the vote is visible in the poll
+	events.Publish(s, events.PollVoted{
+		GuildID:   i.GuildID,
+		ChannelID: i.ChannelID,
+		MessageID: i.Message.ID,
+		Option:    option,
+	})
 }
 
 // makePrivacyToken creates a random token to be hashed with the user's
@@ -2,16 +2,17 @@ package polls
 
 import (
 	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/owobot/internal/shards"
 	"go.elara.ws/owobot/internal/systems/commands"
 	"go.elara.ws/owobot/internal/util"
 )
 
 func Init(s *discordgo.Session) error {
-	s.AddHandler(util.InteractionErrorHandler("poll-add-opt", onPollAddOpt))
-	s.AddHandler(util.InteractionErrorHandler("poll-opt-submit", onAddOptModalSubmit))
-	s.AddHandler(util.InteractionErrorHandler("poll-finish", onPollFinish))
-	s.AddHandler(onPollReaction)
-	s.AddHandler(onVote)
+	commands.AddInteractionHandler(util.InteractionErrorHandler("poll-add-opt", onPollAddOpt))
+	commands.AddInteractionHandler(util.InteractionErrorHandler("poll-opt-submit", onAddOptModalSubmit))
+	commands.AddInteractionHandler(util.InteractionErrorHandler("poll-finish", onPollFinish))
+	shards.AddHandler(onPollReaction)
+	commands.AddInteractionHandler(onVote)
 
 	commands.Register(s, pollCmd, &discordgo.ApplicationCommand{
 		Name:        "poll",
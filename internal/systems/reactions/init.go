@@ -20,12 +20,13 @@ package reactions
 
 import (
 	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/owobot/internal/shards"
 	"go.elara.ws/owobot/internal/systems/commands"
 	"go.elara.ws/owobot/internal/util"
 )
 
 func Init(s *discordgo.Session) error {
-	s.AddHandler(onMessage)
+	shards.AddHandler(onMessage)
 
 	commands.Register(s, reactionsCmd, &discordgo.ApplicationCommand{
 		Name:                     "reactions",
@@ -25,7 +25,7 @@ import (
 )
 
 func Init(s *discordgo.Session) error {
-	s.AddHandler(util.InteractionErrorHandler("on-role-btn", onRoleButton))
+	commands.AddInteractionHandler(util.InteractionErrorHandler("on-role-btn", onRoleButton))
 
 	commands.Register(s, reactionRolesCmd, &discordgo.ApplicationCommand{
 		Name:                     "reaction_roles",
@@ -10,6 +10,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"go.elara.ws/logger/log"
 	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/events"
 	"go.elara.ws/owobot/internal/systems/eventlog"
 	"mvdan.cc/xurls/v2"
 )
@@ -117,6 +118,13 @@ func onReaction(s *discordgo.Session, mra *discordgo.MessageReactionAdd) {
 			log.Warn("Error adding message to starboard").Err(err).Send()
 			return
 		}
+
+		events.Publish(s, events.StarboardAdded{
+			GuildID:   mra.GuildID,
+			ChannelID: mra.ChannelID,
+			Message:   msg,
+			Stars:     len(reactions),
+		})
 	}
 }
 
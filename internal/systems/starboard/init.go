@@ -20,6 +20,7 @@ package starboard
 
 import (
 	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/owobot/internal/shards"
 	"go.elara.ws/owobot/internal/systems/commands"
 	"go.elara.ws/owobot/internal/util"
 )
@@ -30,7 +31,7 @@ const (
 )
 
 func Init(s *discordgo.Session) error {
-	s.AddHandler(onReaction)
+	shards.AddHandler(onReaction)
 
 	commands.Register(s, starboardCmd, &discordgo.ApplicationCommand{
 		Name:                     "starboard",
@@ -9,6 +9,8 @@ import (
 	"go.elara.ws/logger/log"
 	"go.elara.ws/owobot/internal/cache"
 	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/events"
+	"go.elara.ws/owobot/internal/shards"
 	"go.elara.ws/owobot/internal/systems/commands"
 	"go.elara.ws/owobot/internal/systems/eventlog"
 	"go.elara.ws/owobot/internal/util"
@@ -17,7 +19,7 @@ import (
 const ticketPermissions = discordgo.PermissionSendMessages | discordgo.PermissionViewChannel | discordgo.PermissionReadMessageHistory
 
 func Init(s *discordgo.Session) error {
-	s.AddHandler(onMemberLeave)
+	shards.AddHandler(onMemberLeave)
 
 	commands.Register(s, ticketCmd, &discordgo.ApplicationCommand{
 		Name:        "ticket",
@@ -120,6 +122,13 @@ func Open(s *discordgo.Session, guildID string, user, executor *discordgo.User)
 		return "", err
 	}
 
+	events.Publish(s, events.TicketOpened{
+		GuildID:   guildID,
+		ChannelID: c.ID,
+		User:      user,
+		Executor:  executor,
+	})
+
 	return c.ID, eventlog.Log(s, guildID, eventlog.Entry{
 		Title:       "New ticket opened!",
 		Description: "**Executed by:** " + executor.Mention(),
@@ -168,6 +177,13 @@ func Close(s *discordgo.Session, guildID string, user, executor *discordgo.User)
 		return err
 	}
 
+	events.Publish(s, events.TicketClosed{
+		GuildID:   guildID,
+		ChannelID: channelID,
+		User:      user,
+		Executor:  executor,
+	})
+
 	return eventlog.Log(s, guildID, eventlog.Entry{
 		Title:       "Ticket Closed",
 		Description: "**Executed by:** " + executor.Mention(),
@@ -9,6 +9,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"go.elara.ws/owobot/internal/cache"
 	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/events"
 	"go.elara.ws/owobot/internal/systems/eventlog"
 	"go.elara.ws/owobot/internal/systems/tickets"
 	"go.elara.ws/owobot/internal/util"
@@ -142,6 +143,13 @@ func approveCmd(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 		return err
 	}
 
+	events.Publish(s, events.VettingApproved{
+		GuildID:  i.GuildID,
+		User:     user,
+		Role:     role,
+		Approver: i.Member.User,
+	})
+
 	err = eventlog.Log(s, i.GuildID, eventlog.Entry{
 		Title:       "New Member Approved!",
 		Description: fmt.Sprintf("**User:** %s\n**Role:** %s\n**Approved By:** %s", user.Mention(), role.Mention(), i.Member.User.Mention()),
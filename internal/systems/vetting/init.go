@@ -20,6 +20,7 @@ package vetting
 
 import (
 	"github.com/bwmarrin/discordgo"
+	"go.elara.ws/owobot/internal/shards"
 	"go.elara.ws/owobot/internal/systems/commands"
 	"go.elara.ws/owobot/internal/util"
 )
@@ -31,10 +32,10 @@ const (
 )
 
 func Init(s *discordgo.Session) error {
-	s.AddHandler(onMemberJoin)
-	s.AddHandler(util.InteractionErrorHandler("on-vetting-req", onVettingRequest))
-	s.AddHandler(util.InteractionErrorHandler("on-vetting-resp", onVettingResponse))
-	s.AddHandler(onMemberLeave)
+	shards.AddHandler(onMemberJoin)
+	commands.AddInteractionHandler(util.InteractionErrorHandler("on-vetting-req", onVettingRequest))
+	commands.AddInteractionHandler(util.InteractionErrorHandler("on-vetting-resp", onVettingResponse))
+	shards.AddHandler(onMemberLeave)
 
 	commands.Register(s, onMakeVettingMsg, &discordgo.ApplicationCommand{
 		Name:                     "Make Vetting Message",
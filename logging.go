@@ -0,0 +1,235 @@
+/*
+ * owobot - Your server's guardian and entertainer
+ * Copyright (C) 2023 owobot Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+
+	"go.elara.ws/logger"
+	"go.elara.ws/logger/log"
+	"go.elara.ws/owobot/internal/systems/plugins"
+)
+
+// setupLogging configures the core and plugin loggers according to the log configuration
+func setupLogging(cfg Log) error {
+	level, err := logger.ParseLogLevel(cfg.Level)
+	if err != nil {
+		return fmt.Errorf("log level %q: %w", cfg.Level, err)
+	}
+
+	newLogger, err := loggerFunc(cfg.Format)
+	if err != nil {
+		return err
+	}
+
+	log.Logger = newLogger()
+	log.Logger.SetLevel(level)
+
+	// Plugin messages are filtered by the plugin system according
+	// to each plugin's log level, so the plugin logger lets all of them through.
+	plugins.Logger = newLogger()
+	plugins.Logger.SetLevel(logger.LogLevelDebug)
+
+	return nil
+}
+
+// loggerFunc returns a function that creates loggers with the given output format
+func loggerFunc(format string) (func() logger.Logger, error) {
+	switch format {
+	case "pretty":
+		return func() logger.Logger { return logger.NewPretty(os.Stderr) }, nil
+	case "json":
+		return func() logger.Logger { return jsonLogger{logger.NewJSON(lineWriter{os.Stderr})} }, nil
+	default:
+		return nil, fmt.Errorf("unknown log format: %q", format)
+	}
+}
+
+// lineWriter writes a newline after every write. The JSON logger writes
+// each event with a single write and doesn't separate them, so this makes
+// its output one event per line.
+type lineWriter struct {
+	w io.Writer
+}
+
+func (lw lineWriter) Write(b []byte) (int, error) {
+	n, err := lw.w.Write(append(b, '\n'))
+	return min(n, len(b)), err
+}
+
+// jsonLogger wraps the JSON logger to escape messages, keys, and string
+// values, which it writes into its output as-is.
+type jsonLogger struct {
+	*logger.JSONLogger
+}
+
+func (jl jsonLogger) Debug(msg string) logger.LogBuilder {
+	return jsonLogBuilder{jl.JSONLogger.Debug(escapeJSON(msg))}
+}
+
+func (jl jsonLogger) Debugf(format string, v ...any) logger.LogBuilder {
+	return jl.Debug(fmt.Sprintf(format, v...))
+}
+
+func (jl jsonLogger) Info(msg string) logger.LogBuilder {
+	return jsonLogBuilder{jl.JSONLogger.Info(escapeJSON(msg))}
+}
+
+func (jl jsonLogger) Infof(format string, v ...any) logger.LogBuilder {
+	return jl.Info(fmt.Sprintf(format, v...))
+}
+
+func (jl jsonLogger) Warn(msg string) logger.LogBuilder {
+	return jsonLogBuilder{jl.JSONLogger.Warn(escapeJSON(msg))}
+}
+
+func (jl jsonLogger) Warnf(format string, v ...any) logger.LogBuilder {
+	return jl.Warn(fmt.Sprintf(format, v...))
+}
+
+func (jl jsonLogger) Error(msg string) logger.LogBuilder {
+	return jsonLogBuilder{jl.JSONLogger.Error(escapeJSON(msg))}
+}
+
+func (jl jsonLogger) Errorf(format string, v ...any) logger.LogBuilder {
+	return jl.Error(fmt.Sprintf(format, v...))
+}
+
+func (jl jsonLogger) Fatal(msg string) logger.LogBuilder {
+	return jsonLogBuilder{jl.JSONLogger.Fatal(escapeJSON(msg))}
+}
+
+func (jl jsonLogger) Fatalf(format string, v ...any) logger.LogBuilder {
+	return jl.Fatal(fmt.Sprintf(format, v...))
+}
+
+func (jl jsonLogger) Panic(msg string) logger.LogBuilder {
+	return jsonLogBuilder{jl.JSONLogger.Panic(escapeJSON(msg))}
+}
+
+func (jl jsonLogger) Panicf(format string, v ...any) logger.LogBuilder {
+	return jl.Panic(fmt.Sprintf(format, v...))
+}
+
+// jsonLogBuilder wraps a JSON log builder to escape keys and string values,
+// and to encode values the JSON logger can't encode as strings instead.
+type jsonLogBuilder struct {
+	lb logger.LogBuilder
+}
+
+func (jlb jsonLogBuilder) Int(key string, val int) logger.LogBuilder {
+	return jsonLogBuilder{jlb.lb.Int(escapeJSON(key), val)}
+}
+
+func (jlb jsonLogBuilder) Int8(key string, val int8) logger.LogBuilder {
+	return jsonLogBuilder{jlb.lb.Int8(escapeJSON(key), val)}
+}
+
+func (jlb jsonLogBuilder) Int16(key string, val int16) logger.LogBuilder {
+	return jsonLogBuilder{jlb.lb.Int16(escapeJSON(key), val)}
+}
+
+func (jlb jsonLogBuilder) Int32(key string, val int32) logger.LogBuilder {
+	return jsonLogBuilder{jlb.lb.Int32(escapeJSON(key), val)}
+}
+
+func (jlb jsonLogBuilder) Int64(key string, val int64) logger.LogBuilder {
+	return jsonLogBuilder{jlb.lb.Int64(escapeJSON(key), val)}
+}
+
+func (jlb jsonLogBuilder) Uint(key string, val uint) logger.LogBuilder {
+	return jsonLogBuilder{jlb.lb.Uint(escapeJSON(key), val)}
+}
+
+func (jlb jsonLogBuilder) Uint8(key string, val uint8) logger.LogBuilder {
+	return jsonLogBuilder{jlb.lb.Uint8(escapeJSON(key), val)}
+}
+
+func (jlb jsonLogBuilder) Uint16(key string, val uint16) logger.LogBuilder {
+	return jsonLogBuilder{jlb.lb.Uint16(escapeJSON(key), val)}
+}
+
+func (jlb jsonLogBuilder) Uint32(key string, val uint32) logger.LogBuilder {
+	return jsonLogBuilder{jlb.lb.Uint32(escapeJSON(key), val)}
+}
+
+func (jlb jsonLogBuilder) Uint64(key string, val uint64) logger.LogBuilder {
+	return jsonLogBuilder{jlb.lb.Uint64(escapeJSON(key), val)}
+}
+
+func (jlb jsonLogBuilder) Float32(key string, val float32) logger.LogBuilder {
+	return jlb.Float64(key, float64(val))
+}
+
+func (jlb jsonLogBuilder) Float64(key string, val float64) logger.LogBuilder {
+	// JSON has no representation for NaN or infinity
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return jlb.Str(key, strconv.FormatFloat(val, 'f', -1, 64))
+	}
+	return jsonLogBuilder{jlb.lb.Float64(escapeJSON(key), val)}
+}
+
+func (jlb jsonLogBuilder) Stringer(key string, s fmt.Stringer) logger.LogBuilder {
+	return jlb.Str(key, s.String())
+}
+
+func (jlb jsonLogBuilder) Bytes(key string, b []byte) logger.LogBuilder {
+	return jsonLogBuilder{jlb.lb.Bytes(escapeJSON(key), b)}
+}
+
+func (jlb jsonLogBuilder) Timestamp() logger.LogBuilder {
+	return jsonLogBuilder{jlb.lb.Timestamp()}
+}
+
+func (jlb jsonLogBuilder) Bool(key string, val bool) logger.LogBuilder {
+	return jsonLogBuilder{jlb.lb.Bool(escapeJSON(key), val)}
+}
+
+func (jlb jsonLogBuilder) Str(key, val string) logger.LogBuilder {
+	return jsonLogBuilder{jlb.lb.Str(escapeJSON(key), escapeJSON(val))}
+}
+
+func (jlb jsonLogBuilder) Any(key string, val any) logger.LogBuilder {
+	// The JSON logger panics if the value can't be encoded
+	data, err := json.Marshal(val)
+	if err != nil {
+		return jlb.Str(key, fmt.Sprintf("<%s>", err))
+	}
+	return jsonLogBuilder{jlb.lb.Any(escapeJSON(key), json.RawMessage(data))}
+}
+
+func (jlb jsonLogBuilder) Err(err error) logger.LogBuilder {
+	return jlb.Str("error", err.Error())
+}
+
+func (jlb jsonLogBuilder) Send() {
+	jlb.lb.Send()
+}
+
+// escapeJSON escapes s so it can be used inside a JSON string
+func escapeJSON(s string) string {
+	// Marshaling a string never fails
+	data, _ := json.Marshal(s)
+	return string(data[1 : len(data)-1])
+}
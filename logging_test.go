@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"go.elara.ws/logger"
+)
+
+func TestJSONLoggerEscaping(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lg := jsonLogger{logger.NewJSON(lineWriter{buf})}
+
+	const tricky = "quote \" backslash \\ newline \n tab \t"
+	lg.Info(tricky).Send()
+	lg.Warn("fields").
+		Str("str", tricky).
+		Str(tricky, "key").
+		Err(fmt.Errorf("wrapped: %q", tricky)).
+		Stringer("stringer", errStringer{errors.New(tricky)}).
+		Float64("nan", math.NaN()).
+		Float32("inf", float32(math.Inf(1))).
+		Any("func", func() {}).
+		Any("map", map[string]any{"k": tricky}).
+		Send()
+	lg.Errorf("formatted %s", tricky).Int("n", 1).Bool("b", true).Send()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 events, got %d: %q", len(lines), buf.String())
+	}
+
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("invalid JSON output: %s", line)
+		}
+	}
+
+	var event map[string]any
+	err := json.Unmarshal([]byte(lines[1]), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if event["str"] != tricky {
+		t.Errorf("expected str to be %q, got %q", tricky, event["str"])
+	}
+
+	if event[tricky] != "key" {
+		t.Errorf("expected the escaped key to be preserved, got %v", event)
+	}
+
+	if event["nan"] != "NaN" {
+		t.Errorf("expected NaN to be encoded as a string, got %v", event["nan"])
+	}
+}
+
+type errStringer struct {
+	err error
+}
+
+func (es errStringer) String() string {
+	return es.err.Error()
+}
@@ -20,14 +20,18 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 
 	"github.com/bwmarrin/discordgo"
 	"go.elara.ws/logger"
 	"go.elara.ws/logger/log"
 	"go.elara.ws/owobot/internal/db"
+	"go.elara.ws/owobot/internal/shards"
 	"go.elara.ws/owobot/internal/systems/about"
 	"go.elara.ws/owobot/internal/systems/commands"
 	"go.elara.ws/owobot/internal/systems/eventlog"
@@ -47,6 +51,10 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:]))
+	}
+
 	ctx := context.Background()
 	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer cancel()
@@ -56,6 +64,11 @@ func main() {
 		log.Fatal("Error loading configuration").Err(err).Send()
 	}
 
+	err = setupLogging(cfg.Log)
+	if err != nil {
+		log.Fatal("Error setting up logging").Err(err).Send()
+	}
+
 	err = db.Init(ctx, cfg.DBPath+"?_pragma=busy_timeout(30000)")
 	if err != nil {
 		log.Fatal("Error initializing database").Err(err).Send()
@@ -70,25 +83,52 @@ func main() {
 	s.State.TrackMembers = true
 	s.State.TrackRoles = true
 	s.State.TrackChannels = true
-	s.Identify.Intents |= discordgo.IntentMessageContent | discordgo.IntentGuildMembers
+	// The interactions handler changes the transport of the session's
+	// client, so it's set up before anything else can use the session.
+	var interactions http.Handler
+	if cfg.Interactions.Addr != "" {
+		interactions, err = interactionsHandler(s, cfg.Interactions)
+		if err != nil {
+			log.Fatal("Error setting up HTTP interactions endpoint").Err(err).Send()
+		}
+	}
+
+	// Plugins are loaded before the connection is opened, so that
+	// the intents they require can be requested from discord.
+	err = plugins.Load(cfg.PluginDir, cfg.Plugins, s)
+	if err != nil {
+		log.Error("Error loading plugins").Err(err).Send()
+	}
+
+	s.Identify.Intents |= discordgo.IntentMessageContent | discordgo.IntentGuildMembers | plugins.Intents()
+
+	shardCount, maxConcurrency, err := getShardCount(s, cfg.Shards.Count)
+	if err != nil {
+		log.Fatal("Error getting shard count").Err(err).Send()
+	}
 
-	err = s.Open()
+	err = shards.Open(s, shardCount, cfg.Shards.IDs, maxConcurrency)
 	if err != nil {
 		log.Fatal("Error opening a connection to discord").Err(err).Send()
 	}
 
 	if cfg.Activity.Type != -1 && cfg.Activity.Name != "" {
-		err = s.UpdateStatusComplex(discordgo.UpdateStatusData{Activities: []*discordgo.Activity{
-			{Type: cfg.Activity.Type, Name: cfg.Activity.Name},
-		}})
-		if err != nil {
-			log.Error("Error updating status").Err(err).Send()
+		// The status is set per connection, so it has to be updated on every shard
+		for _, sess := range shards.Sessions() {
+			err = sess.UpdateStatusComplex(discordgo.UpdateStatusData{Activities: []*discordgo.Activity{
+				{Type: cfg.Activity.Type, Name: cfg.Activity.Name},
+			}})
+			if err != nil {
+				log.Error("Error updating status").Int("shard", sess.ShardID).Err(err).Send()
+			}
 		}
 	}
 
-	err = plugins.Load(cfg.PluginDir, s)
+	// Plugin init functions may use the connection, so the plugins
+	// are only started once it's open.
+	err = plugins.Start(s)
 	if err != nil {
-		log.Error("Error running plugin file").Err(err).Send()
+		log.Error("Error starting plugins").Err(err).Send()
 	}
 
 	initSystems(
@@ -107,16 +147,50 @@ func main() {
 		commands.Init, // The commands system should always go last
 	)
 
+	// The interactions endpoint is only started once all the
+	// commands and interaction handlers have been registered.
+	if interactions != nil {
+		go serveInteractions(ctx, cfg.Interactions.Addr, interactions)
+	}
+
 	log.Info("Everything is initialized, the bot is ready!").Send()
 
 	select {
 	case <-ctx.Done():
 		log.Info("Context canceled, shutting down...").Send()
-		s.Close()
+		shards.Close()
 		db.Close()
 	}
 }
 
+// getShardCount returns the shard count from the configuration, and the
+// amount of shards that can connect at the same time. If the count is "auto",
+// the shard count recommended by Discord is used.
+func getShardCount(s *discordgo.Session, count string) (int, int, error) {
+	if count == "auto" {
+		gb, err := s.GatewayBot()
+		if err != nil {
+			return 0, 0, err
+		}
+		return gb.Shards, gb.SessionStartLimit.MaxConcurrency, nil
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid shard count: %q", count)
+	} else if n <= 1 {
+		return n, 1, nil
+	}
+
+	// The concurrency limit is only needed when there are multiple shards
+	gb, err := s.GatewayBot()
+	if err != nil {
+		log.Warn("Error getting gateway information, connecting shards one at a time").Err(err).Send()
+		return n, 1, nil
+	}
+	return n, gb.SessionStartLimit.MaxConcurrency, nil
+}
+
 func initSystems(s *discordgo.Session, fns ...func(*discordgo.Session) error) {
 	for i, fn := range fns {
 		err := fn(s)